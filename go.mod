@@ -4,11 +4,13 @@ go 1.21.0
 
 toolchain go1.21.5
 
-require github.com/acexy/golang-toolkit v0.0.38
+require (
+	github.com/acexy/golang-toolkit v0.0.38
+	github.com/sirupsen/logrus v1.9.3
+)
 
 require (
 	github.com/google/uuid v1.6.0 // indirect
-	github.com/sirupsen/logrus v1.9.3 // indirect
 	github.com/timandy/routine v1.1.4 // indirect
 	golang.org/x/sys v0.28.0 // indirect
 	gopkg.in/natefinch/lumberjack.v2 v2.2.1 // indirect
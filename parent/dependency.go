@@ -0,0 +1,178 @@
+package parent
+
+import (
+	"errors"
+	"github.com/acexy/golang-toolkit/util/coll"
+	"sort"
+	"strings"
+)
+
+// 获取模块启动时依赖的模块名称
+func (s *starterWrapper) getDependsOn() []string {
+	setting := s.starter.Setting()
+	if setting == nil {
+		return nil
+	}
+	return setting.dependsOn
+}
+
+// 按名称索引模块 名称重复时仅保留首个模块 忽略未命名的模块
+func namedWrappers(wrappers []*starterWrapper) map[string]*starterWrapper {
+	named := make(map[string]*starterWrapper, len(wrappers))
+	for _, wrapper := range wrappers {
+		setting := wrapper.starter.Setting()
+		if setting == nil || setting.starterName == "" {
+			continue
+		}
+		if _, ok := named[setting.starterName]; !ok {
+			named[setting.starterName] = wrapper
+		}
+	}
+	return named
+}
+
+// 按依赖关系将模块划分为多个层级 同一层级的模块互不依赖，层级内保持原有的注册顺序
+// 依赖的模块不存在或出现循环依赖时返回异常
+func dependencyLevels(wrappers []*starterWrapper) ([][]*starterWrapper, error) {
+	named := namedWrappers(wrappers)
+	depth := make(map[*starterWrapper]int, len(wrappers))
+	visiting := make(map[*starterWrapper]bool)
+	var path []string
+	var resolve func(wrapper *starterWrapper) (int, error)
+	resolve = func(wrapper *starterWrapper) (int, error) {
+		if d, ok := depth[wrapper]; ok {
+			return d, nil
+		}
+		starterName := wrapper.getStarterName()
+		if visiting[wrapper] {
+			return 0, errors.New("circular dependency: " + strings.Join(append(path, starterName), " -> "))
+		}
+		visiting[wrapper] = true
+		path = append(path, starterName)
+		d := 0
+		for _, dependName := range wrapper.getDependsOn() {
+			depend, ok := named[dependName]
+			if !ok {
+				return 0, errors.New("starter " + starterName + " depends on unknown starterName: " + dependName)
+			}
+			dependDepth, err := resolve(depend)
+			if err != nil {
+				return 0, err
+			}
+			d = max(d, dependDepth+1)
+		}
+		path = path[:len(path)-1]
+		visiting[wrapper] = false
+		depth[wrapper] = d
+		return d, nil
+	}
+	levels := make([][]*starterWrapper, 0)
+	for _, wrapper := range wrappers {
+		d, err := resolve(wrapper)
+		if err != nil {
+			return nil, err
+		}
+		for len(levels) <= d {
+			levels = append(levels, make([]*starterWrapper, 0))
+		}
+	}
+	for _, wrapper := range wrappers {
+		levels[depth[wrapper]] = append(levels[depth[wrapper]], wrapper)
+	}
+	return levels, nil
+}
+
+// 按依赖关系排序模块 每个模块依赖的模块在其之前，其余模块保持原有顺序
+// 依赖的模块不存在或出现循环依赖时返回异常
+func dependencyOrder(wrappers []*starterWrapper) ([]*starterWrapper, error) {
+	named := namedWrappers(wrappers)
+	ordered := make([]*starterWrapper, 0, len(wrappers))
+	visited := make(map[*starterWrapper]bool, len(wrappers))
+	visiting := make(map[*starterWrapper]bool)
+	var path []string
+	var visit func(wrapper *starterWrapper) error
+	visit = func(wrapper *starterWrapper) error {
+		if visited[wrapper] {
+			return nil
+		}
+		starterName := wrapper.getStarterName()
+		if visiting[wrapper] {
+			return errors.New("circular dependency: " + strings.Join(append(path, starterName), " -> "))
+		}
+		visiting[wrapper] = true
+		path = append(path, starterName)
+		for _, dependName := range wrapper.getDependsOn() {
+			depend, ok := named[dependName]
+			if !ok {
+				return errors.New("starter " + starterName + " depends on unknown starterName: " + dependName)
+			}
+			if err := visit(depend); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		visiting[wrapper] = false
+		visited[wrapper] = true
+		ordered = append(ordered, wrapper)
+		return nil
+	}
+	for _, wrapper := range wrappers {
+		if err := visit(wrapper); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+// 各模块的依赖方在模块列表中的位置 依赖的模块不存在时忽略
+func dependentIndexes(wrappers []*starterWrapper) [][]int {
+	position := make(map[*starterWrapper]int, len(wrappers))
+	for i, wrapper := range wrappers {
+		position[wrapper] = i
+	}
+	named := namedWrappers(wrappers)
+	dependents := make([][]int, len(wrappers))
+	for i, wrapper := range wrappers {
+		for _, dependName := range wrapper.getDependsOn() {
+			if depend, ok := named[dependName]; ok {
+				dependents[position[depend]] = append(dependents[position[depend]], i)
+			}
+		}
+	}
+	return dependents
+}
+
+// 按stopPriority排序并保证被依赖的模块晚于依赖它的模块停止 返回排序后的模块及各模块生效的停止优先级
+// stopPriority与依赖关系冲突时以依赖关系为准并输出警告日志，依赖关系无法解析时仅按stopPriority排序
+func (s *StarterLoader) stopOrder(wrappers []*starterWrapper) ([]*starterWrapper, []uint) {
+	priority := make(map[*starterWrapper]uint, len(wrappers))
+	for _, wrapper := range wrappers {
+		priority[wrapper] = wrapper.setting().stopPriority
+	}
+	if levels, err := dependencyLevels(wrappers); err != nil {
+		s.log().WithError(err).Warnln("resolve dependency failed, stop by stopPriority only")
+	} else {
+		named := namedWrappers(wrappers)
+		// 依赖它的模块均位于更外的层级 由外向内处理可保证被依赖的模块的优先级在处理前已确定
+		for i := len(levels) - 1; i >= 0; i-- {
+			for _, wrapper := range levels[i] {
+				for _, dependName := range wrapper.getDependsOn() {
+					depend := named[dependName]
+					if priority[depend] <= priority[wrapper] {
+						s.log().Warnln("stopPriority of", dependName, "conflicts with dependent", wrapper.getStarterName(), ", stop it after the dependent")
+						priority[depend] = priority[wrapper] + 1
+					}
+				}
+			}
+		}
+	}
+	sorted := coll.SliceCollect(wrappers, func(item *starterWrapper) *starterWrapper {
+		return item
+	})
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return priority[sorted[i]] < priority[sorted[j]]
+	})
+	return sorted, coll.SliceCollect(sorted, func(item *starterWrapper) uint {
+		return priority[item]
+	})
+}
@@ -0,0 +1,181 @@
+package parent
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// dependent 声明启动依赖的测试模块
+type dependent struct {
+	name      string
+	dependsOn []string
+	record    *[]string
+}
+
+func (d dependent) Setting() *Setting {
+	return NewSetting(d.name, 0, false, time.Second, nil).WithDependsOn(d.dependsOn...)
+}
+
+func (d dependent) Start() (interface{}, error) {
+	*d.record = append(*d.record, d.name)
+	return &d, nil
+}
+
+func (d dependent) Stop(maxWaitTime time.Duration) (gracefully bool, stopped bool, err error) {
+	return true, true, nil
+}
+
+func TestStartByDependency(t *testing.T) {
+	var record []string
+	loader := NewStarterLoader([]Starter{
+		&dependent{name: "gin", dependsOn: []string{"gorm"}, record: &record},
+		&dependent{name: "redis", record: &record},
+		&dependent{name: "gorm", record: &record},
+	})
+	if err := loader.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(record) != "[gorm gin redis]" {
+		t.Fatal("unexpected start order", record)
+	}
+}
+
+func TestStartByDependencyKeepOrder(t *testing.T) {
+	var record []string
+	loader := NewStarterLoader([]Starter{
+		&dependent{name: "a", record: &record},
+		&dependent{name: "b", dependsOn: []string{"a"}, record: &record},
+		&dependent{name: "c", record: &record},
+	})
+	if err := loader.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(record) != "[a b c]" {
+		t.Fatal("modules with met dependencies should keep registration order", record)
+	}
+}
+
+func TestStartByDependencyInvalid(t *testing.T) {
+	var record []string
+	loader := NewStarterLoader([]Starter{
+		&dependent{name: "gin", dependsOn: []string{"gorm"}, record: &record},
+		&dependent{name: "gorm", dependsOn: []string{"gin"}, record: &record},
+	})
+	err := loader.Start()
+	fmt.Println(err)
+	if err == nil || len(record) != 0 {
+		t.Fatal("circular dependency should fail before starting anything")
+	}
+	loader = NewStarterLoader([]Starter{
+		&dependent{name: "gin", dependsOn: []string{"mysql"}, record: &record},
+	})
+	err = loader.Start()
+	fmt.Println(err)
+	if err == nil || len(record) != 0 {
+		t.Fatal("unknown dependency should fail before starting anything")
+	}
+}
+
+func TestStartParallel(t *testing.T) {
+	var record []string
+	loader := NewStarterLoader([]Starter{
+		&sleeper{name: "redis", delay: time.Millisecond * 100},
+		&sleeper{name: "gorm", delay: time.Millisecond * 100},
+		&sleeper{name: "mongo", delay: time.Millisecond * 100},
+		&dependent{name: "gin", dependsOn: []string{"gorm"}, record: &record},
+	})
+	current := time.Now()
+	if err := loader.StartParallel(3); err != nil {
+		t.Fatal(err)
+	}
+	if cost := time.Since(current); cost > time.Millisecond*250 {
+		t.Fatal("independent starters should start concurrently, cost:", cost)
+	}
+	if len(loader.StoppedStarters()) != 0 || fmt.Sprint(record) != "[gin]" {
+		t.Fatal("all starters should be started", loader.StoppedStarters())
+	}
+}
+
+// stopDependent 记录停止顺序并设置了依赖及停止优先级的测试模块
+type stopDependent struct {
+	recorder
+	dependsOn []string
+	priority  uint
+}
+
+func (d stopDependent) Setting() *Setting {
+	return NewSetting(d.name, d.priority, false, time.Second, nil).WithDependsOn(d.dependsOn...)
+}
+
+func TestStopByDependency(t *testing.T) {
+	var record []string
+	loader := NewStarterLoader([]Starter{
+		&stopDependent{recorder: recorder{name: "gorm", record: &record}, priority: 0},
+		&stopDependent{recorder: recorder{name: "redis", record: &record}, priority: 1},
+		&stopDependent{recorder: recorder{name: "gin", record: &record}, dependsOn: []string{"gorm"}, priority: 5},
+	})
+	if err := loader.Start(); err != nil {
+		t.Fatal(err)
+	}
+	record = nil
+	result, err := loader.StopBySetting()
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, len(result))
+	for i, r := range result {
+		names[i] = r.StarterName
+	}
+	if fmt.Sprint(names) != "[redis gin gorm]" || fmt.Sprint(record) != "[redis gin gorm]" {
+		t.Fatal("dependency should stop after its dependent", names, record)
+	}
+}
+
+// asyncDependent 异步停止且停止耗时可控的测试模块
+type asyncDependent struct {
+	name      string
+	dependsOn []string
+	priority  uint
+	delay     time.Duration
+	mu        *sync.Mutex
+	record    *[]string
+}
+
+func (d asyncDependent) Setting() *Setting {
+	return NewSetting(d.name, d.priority, true, time.Second, nil).WithDependsOn(d.dependsOn...)
+}
+
+func (d asyncDependent) Start() (interface{}, error) {
+	return &d, nil
+}
+
+func (d asyncDependent) Stop(maxWaitTime time.Duration) (gracefully bool, stopped bool, err error) {
+	d.mu.Lock()
+	*d.record = append(*d.record, d.name+"-stop")
+	d.mu.Unlock()
+	time.Sleep(d.delay)
+	d.mu.Lock()
+	*d.record = append(*d.record, d.name+"-done")
+	d.mu.Unlock()
+	return true, true, nil
+}
+
+func TestStopWaitsForAsyncDependent(t *testing.T) {
+	var mu sync.Mutex
+	var record []string
+	loader := NewStarterLoader([]Starter{
+		&asyncDependent{name: "db", priority: 0, mu: &mu, record: &record},
+		&asyncDependent{name: "web", dependsOn: []string{"db"}, priority: 0, delay: time.Millisecond * 100, mu: &mu, record: &record},
+	})
+	if err := loader.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loader.StopBySetting(); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(record) != "[web-stop web-done db-stop db-done]" {
+		t.Fatal("dependency should wait for its async dependent", record)
+	}
+}
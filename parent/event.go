@@ -0,0 +1,79 @@
+package parent
+
+import (
+	"time"
+)
+
+// 订阅通道的缓冲大小 缓冲满时新的事件将被丢弃
+const eventBufferSize = 64
+
+const (
+	LifecyclePhaseStarting LifecyclePhase = "starting"
+	LifecyclePhaseStarted  LifecyclePhase = "started"
+	LifecyclePhaseStopping LifecyclePhase = "stopping"
+	LifecyclePhaseStopped  LifecyclePhase = "stopped"
+	LifecyclePhaseFailed   LifecyclePhase = "failed"
+)
+
+// LifecyclePhase 模块生命周期阶段
+type LifecyclePhase string
+
+// LifecycleEvent 模块生命周期事件
+type LifecycleEvent struct {
+	// 模块名称
+	StarterName string
+	// 所处阶段
+	Phase LifecyclePhase
+	// 事件发生时间
+	Timestamp time.Time
+	// 启动或停止的耗时 仅在Started/Stopped/Failed阶段有效
+	Duration time.Duration
+	// 异常信息 仅在Failed阶段有效
+	Err error
+}
+
+// Subscribe 订阅模块生命周期事件
+// 事件以非阻塞的形式投递，订阅方处理过慢导致缓冲已满时，新的事件将被丢弃
+func (s *StarterLoader) Subscribe() <-chan LifecycleEvent {
+	defer s.eventMutex.Unlock()
+	s.eventMutex.Lock()
+	if s.subscribers == nil {
+		s.subscribers = make(map[<-chan LifecycleEvent]chan LifecycleEvent)
+	}
+	ch := make(chan LifecycleEvent, eventBufferSize)
+	s.subscribers[ch] = ch
+	return ch
+}
+
+// Unsubscribe 取消订阅 并关闭对应的事件通道
+func (s *StarterLoader) Unsubscribe(ch <-chan LifecycleEvent) {
+	defer s.eventMutex.Unlock()
+	s.eventMutex.Lock()
+	if subscriber, ok := s.subscribers[ch]; ok {
+		delete(s.subscribers, ch)
+		close(subscriber)
+	}
+}
+
+// 向所有订阅方投递事件
+func (s *StarterLoader) emit(starterName string, phase LifecyclePhase, duration time.Duration, err error) {
+	defer s.eventMutex.Unlock()
+	s.eventMutex.Lock()
+	if len(s.subscribers) == 0 {
+		return
+	}
+	event := LifecycleEvent{
+		StarterName: starterName,
+		Phase:       phase,
+		Timestamp:   time.Now(),
+		Duration:    duration,
+		Err:         err,
+	}
+	for _, subscriber := range s.subscribers {
+		select {
+		case subscriber <- event:
+		default:
+			s.log().Warnln(starterName, "lifecycle event dropped, subscriber is too slow")
+		}
+	}
+}
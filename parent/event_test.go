@@ -0,0 +1,41 @@
+package parent
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSubscribe(t *testing.T) {
+	var record []string
+	loader := NewStarterLoader([]Starter{&recorder{name: "first", record: &record}, &gin{}})
+	events := loader.Subscribe()
+	_ = loader.Start()
+	_, _ = loader.Stop(time.Second)
+	loader.Unsubscribe(events)
+	sequence := make([]string, 0)
+	for event := range events {
+		sequence = append(sequence, event.StarterName+":"+string(event.Phase))
+	}
+	expected := []string{
+		"first:starting", "first:started", "gin:starting", "gin:started",
+		"first:stopping", "first:stopped", "gin:stopping", "gin:failed",
+	}
+	if strings.Join(sequence, ",") != strings.Join(expected, ",") {
+		t.Fatal("unexpected event sequence", sequence)
+	}
+	fmt.Println(sequence)
+}
+
+func TestSubscribeSlowConsumer(t *testing.T) {
+	var record []string
+	loader := NewStarterLoader([]Starter{&recorder{name: "first", record: &record}})
+	events := loader.Subscribe()
+	for i := 0; i < eventBufferSize; i++ {
+		_, _ = loader.RestartStarter("first", time.Second)
+	}
+	if len(events) != eventBufferSize {
+		t.Fatal("events should be buffered up to the limit", len(events))
+	}
+}
@@ -0,0 +1,57 @@
+package parent
+
+import "time"
+
+// FuncStarterOption 函数模块的设置选项
+type FuncStarterOption func(setting *Setting)
+
+// WithFuncStop 设置函数模块的停止参数
+func WithFuncStop(stopPriority uint, stopAllowAsync bool, stopMaxWaitTime time.Duration) FuncStarterOption {
+	return func(setting *Setting) {
+		setting.stopPriority = stopPriority
+		setting.stopAllowAsync = stopAllowAsync
+		setting.stopMaxWaitTime = stopMaxWaitTime
+	}
+}
+
+// WithFuncInitHandler 设置函数模块启动成功后的初始化回调
+func WithFuncInitHandler(initHandler func(instance interface{})) FuncStarterOption {
+	return func(setting *Setting) {
+		setting.initHandler = initHandler
+	}
+}
+
+// funcStarter 由函数实现的模块
+type funcStarter struct {
+	setting *Setting
+	start   func() (interface{}, error)
+	stop    func(maxWaitTime time.Duration) (gracefully, stopped bool, err error)
+}
+
+// NewFuncStarter 使用函数创建一个模块 stop为nil时视为立即优雅停止
+// 默认设置的停止等待时间为0 即使用加载器的默认停止等待时间
+func NewFuncStarter(starterName string, start func() (interface{}, error), stop func(maxWaitTime time.Duration) (gracefully, stopped bool, err error), opts ...FuncStarterOption) Starter {
+	setting := NewSetting(starterName, 0, false, 0, nil)
+	for _, opt := range opts {
+		opt(setting)
+	}
+	return &funcStarter{setting: setting, start: start, stop: stop}
+}
+
+func (f *funcStarter) Setting() *Setting {
+	return f.setting
+}
+
+func (f *funcStarter) Start() (interface{}, error) {
+	if f.start == nil {
+		return nil, nil
+	}
+	return f.start()
+}
+
+func (f *funcStarter) Stop(maxWaitTime time.Duration) (gracefully, stopped bool, err error) {
+	if f.stop == nil {
+		return true, true, nil
+	}
+	return f.stop(maxWaitTime)
+}
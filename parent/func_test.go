@@ -0,0 +1,66 @@
+package parent
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNewFuncStarter(t *testing.T) {
+	var waited time.Duration
+	var initialized interface{}
+	loader := NewStarterLoader([]Starter{
+		NewFuncStarter("cache", func() (interface{}, error) {
+			return "cache-instance", nil
+		}, nil),
+		NewFuncStarter("queue", func() (interface{}, error) {
+			return "queue-instance", nil
+		}, func(maxWaitTime time.Duration) (bool, bool, error) {
+			waited = maxWaitTime
+			return true, true, nil
+		}, WithFuncStop(1, false, time.Second*2), WithFuncInitHandler(func(instance interface{}) {
+			initialized = instance
+		}), func(setting *Setting) {
+			setting.WithDependsOn("cache")
+		}),
+	})
+	if err := loader.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if initialized != "queue-instance" {
+		t.Fatal("init handler not invoked", initialized)
+	}
+	if instance, _ := loader.GetInstance("cache"); instance != "cache-instance" {
+		t.Fatal("unexpected instance", instance)
+	}
+	result, err := loader.StopBySetting()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// queue依赖cache 即使cache的stopPriority更小也将在queue之后停止
+	if len(result) != 2 || result[0].StarterName != "queue" || result[1].StarterName != "cache" {
+		t.Fatal("unexpected stop order", result[0].StarterName, result[1].StarterName)
+	}
+	for _, r := range result {
+		if !r.Stopped || !r.Gracefully || r.Error != nil {
+			t.Fatal("func starter should stop gracefully", r)
+		}
+	}
+	if waited != time.Second*2 {
+		t.Fatal("stop wait not applied", waited)
+	}
+}
+
+func TestNewFuncStarterStartError(t *testing.T) {
+	loader := NewStarterLoader([]Starter{
+		NewFuncStarter("broken", func() (interface{}, error) {
+			return nil, errors.New("broken")
+		}, nil),
+	})
+	if err := loader.Start(); err == nil {
+		t.Fatal("start error should be returned")
+	}
+	if status, _ := loader.GetStatus("broken"); status == StarterStatusStarted {
+		t.Fatal("failed func starter should not be started")
+	}
+}
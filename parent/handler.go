@@ -0,0 +1,79 @@
+package parent
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// StarterReport 单个模块的状态报告
+type StarterReport struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// HealthReport 健康检查/存活检查的响应内容
+type HealthReport struct {
+	Healthy  bool             `json:"healthy"`
+	Starters []*StarterReport `json:"starters"`
+}
+
+// HealthHandler 创建就绪检查的http处理器
+// 所有启用的模块均已启动且健康时返回200，否则返回503，响应内容为各模块的名称、状态及异常信息
+func HealthHandler(loader *StarterLoader) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		health := loader.Health(r.Context())
+		report := loader.report(func(report *StarterReport) {
+			if err, ok := health[report.Name]; ok && err != nil {
+				report.Error = err.Error()
+			}
+		})
+		report.Healthy = loader.snapshot().allStarted()
+		for _, v := range report.Starters {
+			if v.Error != "" {
+				report.Healthy = false
+			}
+		}
+		writeReport(w, report)
+	})
+}
+
+// LivenessHandler 创建存活检查的http处理器 所有模块均已启动时返回200，否则返回503
+func LivenessHandler(loader *StarterLoader) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := loader.report(nil)
+		report.Healthy = loader.snapshot().allStarted()
+		writeReport(w, report)
+	})
+}
+
+// 生成所有模块的状态报告
+func (s *StarterLoader) report(fill func(report *StarterReport)) *HealthReport {
+	report := &HealthReport{Starters: make([]*StarterReport, 0)}
+	starters := s.snapshot()
+	if starters.isEmpty() {
+		return report
+	}
+	for _, wrapper := range *starters {
+		starterReport := &StarterReport{
+			Name:   wrapper.getStarterName(),
+			Status: wrapper.getStatus().String(),
+		}
+		if fill != nil {
+			fill(starterReport)
+		}
+		report.Starters = append(report.Starters, starterReport)
+	}
+	return report
+}
+
+// 输出状态报告
+func writeReport(w http.ResponseWriter, report *HealthReport) {
+	w.Header().Set("Content-Type", "application/json")
+	if report.Healthy {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_ = json.NewEncoder(w).Encode(report)
+}
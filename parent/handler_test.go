@@ -0,0 +1,59 @@
+package parent
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHealthHandler(t *testing.T) {
+	loader := NewStarterLoader([]Starter{
+		&checked{sleeper: sleeper{name: "healthy"}},
+		&checked{sleeper: sleeper{name: "broken"}, err: errors.New("connection refused")},
+	})
+	_ = loader.Start()
+	recorder := httptest.NewRecorder()
+	HealthHandler(loader).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Fatal("unexpected status code", recorder.Code)
+	}
+	var report HealthReport
+	if err := json.Unmarshal(recorder.Body.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	fmt.Println(recorder.Body.String())
+	if report.Healthy || len(report.Starters) != 2 || report.Starters[1].Error != "connection refused" || report.Starters[1].Status != "started" {
+		t.Fatal("unexpected report", recorder.Body.String())
+	}
+
+	loader = NewStarterLoader([]Starter{&checked{sleeper: sleeper{name: "healthy"}}})
+	recorder = httptest.NewRecorder()
+	HealthHandler(loader).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Fatal("not started loader should not be ready", recorder.Code)
+	}
+	_ = loader.Start()
+	recorder = httptest.NewRecorder()
+	HealthHandler(loader).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatal("unexpected status code", recorder.Code)
+	}
+}
+
+func TestLivenessHandler(t *testing.T) {
+	loader := NewStarterLoader([]Starter{&sleeper{name: "first"}})
+	recorder := httptest.NewRecorder()
+	LivenessHandler(loader).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/livez", nil))
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Fatal("not started loader should not be live", recorder.Code)
+	}
+	_ = loader.Start()
+	recorder = httptest.NewRecorder()
+	LivenessHandler(loader).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/livez", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatal("started loader should be live", recorder.Code)
+	}
+}
@@ -0,0 +1,85 @@
+package parent
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// HealthChecker 可选实现的健康检查接口
+type HealthChecker interface {
+	// HealthCheck 检查模块是否健康 返回nil表示健康
+	HealthCheck(ctx context.Context) error
+}
+
+// HealthCheckFunc 以函数实现的健康检查
+type HealthCheckFunc func(ctx context.Context) error
+
+func (f HealthCheckFunc) HealthCheck(ctx context.Context) error {
+	return f(ctx)
+}
+
+// 获取模块的健康检查 模块实现的HealthChecker优先于Setting中设置的健康检查
+func (s *starterWrapper) healthChecker() (HealthChecker, bool) {
+	if checker, ok := s.starter.(HealthChecker); ok {
+		return checker, true
+	}
+	if setting := s.starter.Setting(); setting != nil && setting.healthCheck != nil {
+		return setting.healthCheck, true
+	}
+	return nil, false
+}
+
+// Health 对所有已启动的模块执行健康检查 返回模块名称与检查结果，nil表示健康
+// 未实现HealthChecker且未设置健康检查的模块默认视为健康，ctx结束时仍未返回的检查将以ctx.Err()作为结果
+func (s *StarterLoader) Health(ctx context.Context) map[string]error {
+	health := make(map[string]error)
+	starters := s.snapshot()
+	if starters.isEmpty() {
+		return health
+	}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, wrapper := range *starters {
+		if wrapper.getStatus() != StarterStatusStarted {
+			continue
+		}
+		starterName := wrapper.getStarterName()
+		checker, ok := wrapper.healthChecker()
+		if !ok {
+			mu.Lock()
+			health[starterName] = nil
+			mu.Unlock()
+			continue
+		}
+		wg.Add(1)
+		go func(starterName string, checker HealthChecker) {
+			defer wg.Done()
+			err := check(ctx, starterName, checker)
+			mu.Lock()
+			health[starterName] = err
+			mu.Unlock()
+		}(starterName, checker)
+	}
+	wg.Wait()
+	return health
+}
+
+// 执行健康检查 ctx结束时不再等待检查结果
+func check(ctx context.Context, starterName string, checker HealthChecker) error {
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("%s health check panic: %v", starterName, r)
+			}
+		}()
+		done <- checker.HealthCheck(ctx)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,71 @@
+package parent
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// checked 实现HealthChecker的测试模块
+type checked struct {
+	sleeper
+	err  error
+	hang bool
+}
+
+func (c checked) Setting() *Setting {
+	return NewSetting(c.name, 0, false, time.Second, nil)
+}
+
+func (c checked) HealthCheck(ctx context.Context) error {
+	if c.hang {
+		time.Sleep(time.Second)
+	}
+	return c.err
+}
+
+func TestHealth(t *testing.T) {
+	loader := NewStarterLoader([]Starter{
+		&checked{sleeper: sleeper{name: "healthy"}},
+		&checked{sleeper: sleeper{name: "broken"}, err: errors.New("connection refused")},
+		&checked{sleeper: sleeper{name: "hung"}, hang: true},
+		&sleeper{name: "plain"},
+		&sleeper{name: "idle"},
+	})
+	_ = loader.StartStarter("healthy")
+	_ = loader.StartStarter("broken")
+	_ = loader.StartStarter("hung")
+	_ = loader.StartStarter("plain")
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+	defer cancel()
+	current := time.Now()
+	health := loader.Health(ctx)
+	if time.Since(current) > time.Millisecond*500 {
+		t.Fatal("hung check should not block the report")
+	}
+	if len(health) != 4 {
+		t.Fatal("only started modules should be checked", health)
+	}
+	if health["healthy"] != nil || health["plain"] != nil || health["broken"] == nil {
+		t.Fatal("unexpected health", health)
+	}
+	if !errors.Is(health["hung"], context.DeadlineExceeded) {
+		t.Fatal("hung check should report deadline", health["hung"])
+	}
+}
+
+func TestSettingHealthCheck(t *testing.T) {
+	loader := NewStarterLoader([]Starter{
+		NewFuncStarter("cache", nil, nil, func(setting *Setting) {
+			setting.WithHealthCheck(func(ctx context.Context) error {
+				return errors.New("evicted")
+			})
+		}),
+	})
+	_ = loader.Start()
+	health := loader.Health(context.Background())
+	if err, ok := health["cache"]; !ok || err == nil || err.Error() != "evicted" {
+		t.Fatal("setting health check should be used", health)
+	}
+}
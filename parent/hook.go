@@ -0,0 +1,110 @@
+package parent
+
+import (
+	"runtime/debug"
+	"sync"
+)
+
+// 加载器级别的启动/停止回调 按注册顺序依次执行
+type hooks struct {
+	sync.RWMutex
+	beforeStart []func(starterName string)
+	afterStart  []func(starterName string, instance interface{}, err error)
+	beforeStop  []func(starterName string)
+	afterStop   []func(starterName string, result *StopResult)
+}
+
+// 复制已注册的回调 用于克隆加载器
+func (h *hooks) clone() hooks {
+	defer h.RUnlock()
+	h.RLock()
+	return hooks{
+		beforeStart: append([]func(starterName string){}, h.beforeStart...),
+		afterStart:  append([]func(starterName string, instance interface{}, err error){}, h.afterStart...),
+		beforeStop:  append([]func(starterName string){}, h.beforeStop...),
+		afterStop:   append([]func(starterName string, result *StopResult){}, h.afterStop...),
+	}
+}
+
+// OnBeforeStart 注册在每个模块启动前执行的回调 回调在启动流程中同步执行，实现应当快速返回
+func (s *StarterLoader) OnBeforeStart(hook func(starterName string)) {
+	defer s.lifecycleHooks.Unlock()
+	s.lifecycleHooks.Lock()
+	s.lifecycleHooks.beforeStart = append(s.lifecycleHooks.beforeStart, hook)
+}
+
+// OnAfterStart 注册在每个模块启动完成(包含重试及初始化)后执行的回调 err不为nil表示启动失败，此时instance为nil
+func (s *StarterLoader) OnAfterStart(hook func(starterName string, instance interface{}, err error)) {
+	defer s.lifecycleHooks.Unlock()
+	s.lifecycleHooks.Lock()
+	s.lifecycleHooks.afterStart = append(s.lifecycleHooks.afterStart, hook)
+}
+
+// OnBeforeStop 注册在每个模块执行停止前执行的回调 未启动而跳过停止的模块不执行
+// 按设置停止时异步停止的模块将并发执行回调，实现应当并发安全
+func (s *StarterLoader) OnBeforeStop(hook func(starterName string)) {
+	defer s.lifecycleHooks.Unlock()
+	s.lifecycleHooks.Lock()
+	s.lifecycleHooks.beforeStop = append(s.lifecycleHooks.beforeStop, hook)
+}
+
+// OnAfterStop 注册在每个模块完成停止后执行的回调 适用于刷新缓冲、记录审计事件等
+func (s *StarterLoader) OnAfterStop(hook func(starterName string, result *StopResult)) {
+	defer s.lifecycleHooks.Unlock()
+	s.lifecycleHooks.Lock()
+	s.lifecycleHooks.afterStop = append(s.lifecycleHooks.afterStop, hook)
+}
+
+// 执行模块启动前的回调
+func (s *StarterLoader) beforeStart(starterName string) {
+	defer s.lifecycleHooks.RUnlock()
+	s.lifecycleHooks.RLock()
+	for _, hook := range s.lifecycleHooks.beforeStart {
+		s.invokeCallback(starterName, "beforeStart hook", func() {
+			hook(starterName)
+		})
+	}
+}
+
+// 执行模块启动后的回调
+func (s *StarterLoader) afterStart(result *StartResult) {
+	defer s.lifecycleHooks.RUnlock()
+	s.lifecycleHooks.RLock()
+	for _, hook := range s.lifecycleHooks.afterStart {
+		s.invokeCallback(result.StarterName, "afterStart hook", func() {
+			hook(result.StarterName, result.instance, result.Error)
+		})
+	}
+}
+
+// 执行模块停止前的回调
+func (s *StarterLoader) beforeStop(starterName string) {
+	defer s.lifecycleHooks.RUnlock()
+	s.lifecycleHooks.RLock()
+	for _, hook := range s.lifecycleHooks.beforeStop {
+		s.invokeCallback(starterName, "beforeStop hook", func() {
+			hook(starterName)
+		})
+	}
+}
+
+// 执行模块停止后的回调
+func (s *StarterLoader) afterStop(result *StopResult) {
+	defer s.lifecycleHooks.RUnlock()
+	s.lifecycleHooks.RLock()
+	for _, hook := range s.lifecycleHooks.afterStop {
+		s.invokeCallback(result.StarterName, "afterStop hook", func() {
+			hook(result.StarterName, result)
+		})
+	}
+}
+
+// 执行加载器的回调(启动/停止回调、指标记录、链路追踪) 回调panic时输出异常日志，不影响启动/停止流程
+func (s *StarterLoader) invokeCallback(starterName, callback string, fn func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.log().Errorf("%s %s panic: %v\n%s", starterName, callback, r, debug.Stack())
+		}
+	}()
+	fn()
+}
@@ -0,0 +1,125 @@
+package parent
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStartHooks(t *testing.T) {
+	loader := NewStarterLoader([]Starter{&redis{}, &gorm{}, &gin{}, &failing{name: "broken"}})
+	calls := make([]string, 0)
+	loader.OnBeforeStart(func(starterName string) {
+		calls = append(calls, "before1:"+starterName)
+	})
+	loader.OnBeforeStart(func(starterName string) {
+		calls = append(calls, "before2:"+starterName)
+	})
+	loader.OnAfterStart(func(starterName string, instance interface{}, err error) {
+		calls = append(calls, fmt.Sprintf("after:%s:%T:%v", starterName, instance, err))
+	})
+	if err := loader.Start(); err == nil {
+		t.Fatal("broken starter should fail")
+	}
+	expected := "[before1:unnamed-1 before2:unnamed-1 after:unnamed-1:*parent.redis:<nil> " +
+		"before1:gorm before2:gorm after:gorm:*parent.gorm:<nil> " +
+		"before1:gin before2:gin after:gin:*parent.gin:<nil> " +
+		"before1:broken before2:broken after:broken:<nil>:broken start failed]"
+	if fmt.Sprint(calls) != expected {
+		t.Fatal("unexpected hook calls", calls)
+	}
+}
+
+func TestStopHooks(t *testing.T) {
+	loader := NewStarterLoader([]Starter{&redis{}, &gorm{}, &gin{}, &sleeper{name: "idle"}})
+	if err := loader.StartStarter("gorm"); err != nil {
+		t.Fatal(err)
+	}
+	if err := loader.StartStarter("gin"); err != nil {
+		t.Fatal(err)
+	}
+	if err := loader.StartStarter("unnamed-1"); err != nil {
+		t.Fatal(err)
+	}
+	var mu sync.Mutex
+	before := make([]string, 0)
+	after := make(map[string]*StopResult)
+	loader.OnBeforeStop(func(starterName string) {
+		mu.Lock()
+		defer mu.Unlock()
+		before = append(before, starterName)
+	})
+	loader.OnAfterStop(func(starterName string, result *StopResult) {
+		mu.Lock()
+		defer mu.Unlock()
+		after[starterName] = result
+	})
+	result, err := loader.StopBySetting()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	sort.Strings(before)
+	if fmt.Sprint(before) != "[gin gorm unnamed-1]" {
+		t.Fatal("before-stop hooks should fire for every started module", before)
+	}
+	if len(after) != 3 || after["idle"] != nil {
+		t.Fatal("after-stop hooks should skip not started modules", after)
+	}
+	gin := after["gin"]
+	if gin == nil || gin.Error == nil || gin.Stopped || gin != result[0] {
+		t.Fatalf("after-stop hook should see the gin failure %+v", gin)
+	}
+	if !after["gorm"].Stopped || !after["unnamed-1"].Stopped {
+		t.Fatal("async stops should be reported", after)
+	}
+}
+
+// panickingRecorder 记录指标时panic的指标记录
+type panickingRecorder struct{}
+
+func (panickingRecorder) RecordStart(string, time.Duration, error) {
+	panic("record start")
+}
+
+func (panickingRecorder) RecordStop(string, time.Duration, *StopResult) {
+	panic("record stop")
+}
+
+// panickingTracer 创建span时panic的链路追踪
+type panickingTracer struct{}
+
+func (panickingTracer) Start(context.Context, string) (context.Context, Span) {
+	panic("trace")
+}
+
+func TestCallbackPanic(t *testing.T) {
+	loader := NewStarterLoaderWith(
+		WithStarters(&sleeper{name: "first"}, &sleeper{name: "second"}),
+		WithMetricsRecorder(panickingRecorder{}),
+		WithTracer(panickingTracer{}),
+	)
+	loader.OnBeforeStart(func(string) { panic("before start") })
+	loader.OnAfterStart(func(string, interface{}, error) { panic("after start") })
+	loader.OnBeforeStop(func(string) { panic("before stop") })
+	loader.OnAfterStop(func(string, *StopResult) { panic("after stop") })
+	if err := loader.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(loader.StartedStarters()) != "[first second]" {
+		t.Fatal("panicking callbacks should not break start", loader.StartedStarters())
+	}
+	result, err := loader.StopBySetting()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range result {
+		if r.Error != nil || !r.Stopped {
+			t.Fatalf("panicking callbacks should not break stop %+v", r)
+		}
+	}
+}
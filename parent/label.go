@@ -0,0 +1,85 @@
+package parent
+
+import (
+	"context"
+	"errors"
+	"github.com/acexy/golang-toolkit/util/coll"
+	"time"
+)
+
+// 模块是否具有指定的标签
+func (s *starterWrapper) hasLabel(key, value string) bool {
+	setting := s.starter.Setting()
+	if setting == nil || setting.labels == nil {
+		return false
+	}
+	v, ok := setting.labels[key]
+	return ok && v == value
+}
+
+// 按注册顺序筛选具有指定标签的模块
+func (s *starterWrappers) withLabel(key, value string) []*starterWrapper {
+	return coll.SliceFilter(*s, func(item *starterWrapper) bool {
+		return item.hasLabel(key, value)
+	})
+}
+
+// GetLabels 获取指定模块的标签 返回标签的副本
+func (s *StarterLoader) GetLabels(starterName string) (map[string]string, error) {
+	starters := s.snapshot()
+	if starters.isEmpty() {
+		return nil, errors.New("no starter")
+	}
+	wrapper := starters.find(starterName)
+	if wrapper == nil {
+		return nil, errors.New("unknown starterName: " + starterName)
+	}
+	labels := make(map[string]string)
+	if setting := wrapper.starter.Setting(); setting != nil {
+		for k, v := range setting.labels {
+			labels[k] = v
+		}
+	}
+	return labels, nil
+}
+
+// StartersByLabel 具有指定标签的模块名 按注册顺序返回
+func (s *StarterLoader) StartersByLabel(key, value string) []string {
+	starters := s.snapshot()
+	if starters.isEmpty() {
+		return nil
+	}
+	return coll.SliceCollect(starters.withLabel(key, value), func(item *starterWrapper) string {
+		return item.getStarterName()
+	})
+}
+
+// StartByLabel 按注册顺序启动具有指定标签的未启动模块
+func (s *StarterLoader) StartByLabel(key, value string) error {
+	defer s.Mutex.Unlock()
+	s.Mutex.Lock()
+	if s.starters.isEmpty() {
+		return errors.New("miss starters")
+	}
+	for _, wrapper := range s.starters.withLabel(key, value) {
+		if err := s.start(wrapper); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StopByLabel 按注册顺序停止具有指定标签的模块
+func (s *StarterLoader) StopByLabel(key, value string, maxWaitTime time.Duration) ([]*StopResult, error) {
+	defer s.Mutex.Unlock()
+	s.Mutex.Lock()
+	if s.starters.isEmpty() {
+		return nil, errors.New("no starter")
+	}
+	wrappers := s.starters.withLabel(key, value)
+	stopResult := make([]*StopResult, len(wrappers))
+	for i, wrapper := range wrappers {
+		stopResult[i] = s.stop(context.Background(), wrapper, maxWaitTime)
+	}
+	return stopResult, nil
+}
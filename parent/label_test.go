@@ -0,0 +1,69 @@
+package parent
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// labeled 带有标签的测试模块
+type labeled struct {
+	recorder
+	labels map[string]string
+}
+
+func (l labeled) Setting() *Setting {
+	return NewSetting(l.name, 0, false, time.Second, nil).WithLabels(l.labels)
+}
+
+func TestStopByLabel(t *testing.T) {
+	var record []string
+	loader := NewStarterLoader([]Starter{
+		&labeled{recorder: recorder{name: "gin", record: &record}, labels: map[string]string{"tier": "web"}},
+		&labeled{recorder: recorder{name: "redis", record: &record}, labels: map[string]string{"tier": "infra"}},
+		&labeled{recorder: recorder{name: "grpc", record: &record}, labels: map[string]string{"tier": "web"}},
+	})
+	if fmt.Sprint(loader.StartersByLabel("tier", "web")) != "[gin grpc]" {
+		t.Fatal("unexpected labeled starters", loader.StartersByLabel("tier", "web"))
+	}
+	if err := loader.StartByLabel("tier", "web"); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(loader.StartedStarters()) != "[gin grpc]" {
+		t.Fatal("only labeled modules should start", loader.StartedStarters())
+	}
+	if err := loader.Start(); err != nil {
+		t.Fatal(err)
+	}
+	result, err := loader.StopByLabel("tier", "web", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 2 || result[0].StarterName != "gin" || result[1].StarterName != "grpc" || !result[0].Stopped || !result[1].Stopped {
+		t.Fatal("unexpected stop results", result)
+	}
+	if fmt.Sprint(loader.StartedStarters()) != "[redis]" {
+		t.Fatal("unlabeled module should keep running", loader.StartedStarters())
+	}
+}
+
+func TestGetLabels(t *testing.T) {
+	loader := NewStarterLoader([]Starter{
+		&labeled{recorder: recorder{name: "gin"}, labels: map[string]string{"tier": "web"}},
+		&sleeper{name: "redis"},
+	})
+	labels, err := loader.GetLabels("gin")
+	if err != nil || labels["tier"] != "web" {
+		t.Fatal("unexpected labels", labels, err)
+	}
+	labels["tier"] = "changed"
+	if labels, _ = loader.GetLabels("gin"); labels["tier"] != "web" {
+		t.Fatal("labels should be copied", labels)
+	}
+	if labels, err = loader.GetLabels("redis"); err != nil || len(labels) != 0 {
+		t.Fatal("module without labels should return empty labels", labels, err)
+	}
+	if _, err = loader.GetLabels("missing"); err == nil {
+		t.Fatal("unknown module should return error")
+	}
+}
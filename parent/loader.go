@@ -1,26 +1,110 @@
 package parent
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
-	"github.com/acexy/golang-toolkit/logger"
+	"fmt"
 	"github.com/acexy/golang-toolkit/util/coll"
+	"github.com/sirupsen/logrus"
+	"os"
+	"reflect"
+	"runtime/debug"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 var loader *StarterLoader
 var once sync.Once
 
+// 模块启动成功的全局序号 用于按启动的相反顺序停止模块
+var startSequence atomic.Uint64
+
 const (
-	StarterStatusStarted StarterStatus = 1
-	StarterStatusStopped               = -1
+	StarterStatusNotStarted StarterStatus = 0
+	StarterStatusStarted    StarterStatus = 1
+	StarterStatusStopped    StarterStatus = -1
+	StarterStatusStarting   StarterStatus = 2
+	StarterStatusStopping   StarterStatus = -2
 )
 
 type StarterStatus int8
 
+func (s StarterStatus) String() string {
+	switch s {
+	case StarterStatusNotStarted:
+		return "not started"
+	case StarterStatusStarted:
+		return "started"
+	case StarterStatusStopped:
+		return "stopped"
+	case StarterStatusStarting:
+		return "starting"
+	case StarterStatusStopping:
+		return "stopping"
+	}
+	return fmt.Sprintf("StarterStatus(%d)", int8(s))
+}
+
+const (
+	// DuplicateStartIgnore 忽略对已启动模块的启动
+	DuplicateStartIgnore DuplicateStartPolicy = 0
+	// DuplicateStartError 启动已启动的模块时返回异常
+	DuplicateStartError DuplicateStartPolicy = 1
+)
+
+// DuplicateStartPolicy 启动已启动的模块时的处理策略
+type DuplicateStartPolicy int8
+
 type StarterLoader struct {
+	// 生命周期操作锁 启动/停止/变更模块时持有
 	sync.Mutex
-	starters *starterWrappers
+	// 模块列表读写锁 只读的状态查询仅持有该锁，不会被耗时的启动/停止阻塞
+	startersMutex sync.RWMutex
+	starters      *starterWrappers
+	// 日志输出 未设置时使用toolkit的全局日志
+	logger *logrus.Logger
+	// 模块未设置等待时间时使用的默认停止等待时间
+	defaultStopWait time.Duration
+	// 出现重复的模块名称时是否返回异常 否则仅输出警告日志
+	strictNames bool
+	// 出现未命名的模块时是否返回异常 否则为其分配唯一的名称
+	requireNames bool
+	// 出现未设置Setting的模块时是否返回异常 否则使用默认设置
+	requireSettings bool
+	// 已分配的未命名模块名称数量
+	unnamedSeq int
+	// 启动阶段的先后顺序
+	phases []string
+	// 按设置停止时异步停止模块的最大并发数 0表示不限制
+	maxAsyncStops int
+	// 启动/停止耗时的指标记录 未设置时不记录
+	metricsRecorder MetricsRecorder
+	// 按设置停止时是否在结果中省略因未启动而跳过的模块
+	omitSkippedStops bool
+	// 通过StartStarter启动已启动的模块时的处理策略
+	duplicateStartPolicy DuplicateStartPolicy
+	// 分阶段停止时首次尝试的等待时间占完整等待时间的比例 0表示不分阶段
+	softStopFraction float64
+	// 模块停止进度的接收方 未设置时忽略模块汇报的进度
+	progressSink func(progress StopProgress)
+	// 启动/停止的链路追踪 未设置时不追踪
+	lifecycleTracer Tracer
+	// 替代signal.Notify的信号来源 用于测试
+	signals chan os.Signal
+	// 启动/停止回调
+	lifecycleHooks hooks
+	// 开始停止所有模块时取消的context 首次获取时创建
+	shutdownMutex  sync.Mutex
+	shutdownCtx    context.Context
+	shutdownCancel context.CancelFunc
+	shutdownBegun  bool
+	// 生命周期事件订阅方
+	eventMutex  sync.Mutex
+	subscribers map[<-chan LifecycleEvent]chan LifecycleEvent
 }
 
 type Starter interface {
@@ -39,49 +123,235 @@ type Starter interface {
 	Stop(maxWaitTime time.Duration) (gracefully, stopped bool, err error)
 }
 
+// Forcible 可选实现的强制停止接口 当模块未能优雅停机(gracefully=false)时，loader将调用ForceStop强制停止模块
+type Forcible interface {
+	ForceStop() error
+}
+
+// ContextStarter 可选实现的停止接口 停止时由loader传入带有截止时间的context
+// 当模块同时实现了Starter.Stop与ContextStarter.StopWithContext时，loader优先调用StopWithContext
+// 		ctx 截止时间取模块等待时间与全局等待时间中较早的一个
+type ContextStarter interface {
+	StopWithContext(ctx context.Context) (gracefully, stopped bool, err error)
+}
+
 // 包裹原始Starter做未来拓展
 type starterWrapper struct {
-	// 状态 0=未启动 1=已启动 -1=已停止
-	status  StarterStatus
+	// 状态 0=未启动 1=已启动 -1=已停止 2=启动中 -2=停止中 以原子方式读写
+	status  atomic.Int32
 	starter Starter
+	// 模块在加载器模块列表中的位置 不受启动/停止排序的影响
+	index int
+	// 模块未设置名称时由加载器分配的名称
+	generatedName string
+	// 最近一次成功启动时模块返回的实例
+	instance interface{}
+	// 最近一次成功启动的序号
+	startSeq uint64
+	// 最近一次启动/停止的异常 成功时清除 以原子方式读写
+	lastErr atomic.Pointer[error]
+}
+
+// 记录最近一次启动/停止的异常
+func (s *starterWrapper) setLastError(err error) {
+	if err == nil {
+		s.lastErr.Store(nil)
+		return
+	}
+	s.lastErr.Store(&err)
+}
+
+// 获取最近一次启动/停止的异常
+func (s *starterWrapper) getLastError() error {
+	if err := s.lastErr.Load(); err != nil {
+		return *err
+	}
+	return nil
+}
+
+// 获取模块状态
+func (s *starterWrapper) getStatus() StarterStatus {
+	return StarterStatus(s.status.Load())
+}
+
+// 设置模块状态
+func (s *starterWrapper) setStatus(status StarterStatus) {
+	s.status.Store(int32(status))
+}
+
+// 标记模块已启动并缓存启动返回的实例
+func (s *starterWrapper) markStarted(result *StartResult) {
+	s.setStatus(StarterStatusStarted)
+	s.instance = result.instance
+	s.startSeq = startSequence.Add(1)
+}
+
+// 模块是否处于可启动的状态 (未启动或已停止)
+func (s *starterWrapper) startable() bool {
+	status := s.getStatus()
+	return status == StarterStatusNotStarted || status == StarterStatusStopped
+}
+
+// 模块是否被设置为不启用
+func (s *starterWrapper) disabled() bool {
+	setting := s.starter.Setting()
+	return setting != nil && setting.enabled != nil && !setting.enabled()
+}
+
+// 标记模块启动中 并返回启动前的状态 同时清除上一次启动返回的实例
+func (s *starterWrapper) markStarting() StarterStatus {
+	previous := s.getStatus()
+	s.setStatus(StarterStatusStarting)
+	s.instance = nil
+	return previous
 }
 
-// 获取Starter名称
+// 标记模块启动中 停止所有模块后再次启动模块时重新创建ShutdownContext 调用方需持有锁
+func (s *StarterLoader) markStarting(wrapper *starterWrapper) StarterStatus {
+	s.resetShutdown()
+	return wrapper.markStarting()
+}
+
+// 根据启动结果更新模块状态 启动失败时恢复为启动前的状态
+func (s *starterWrapper) finishStart(result *StartResult, previous StarterStatus) {
+	s.setLastError(result.Error)
+	if result.Error != nil {
+		s.setStatus(previous)
+		return
+	}
+	s.markStarted(result)
+}
+
+// 获取Starter名称 未设置名称时返回加载器分配的名称
 func (s *starterWrapper) getStarterName() string {
 	setting := s.starter.Setting()
 	if setting != nil && setting.starterName != "" {
 		return setting.starterName
 	}
+	if s.generatedName != "" {
+		return s.generatedName
+	}
 	return "unnamed"
 }
 
+// 获取模块设置 模块未设置Setting时返回默认设置
+// 默认设置的停止优先级为0、不允许异步停止、使用加载器的默认停止等待时间，启动与停止均使用该设置
+func (s *starterWrapper) setting() *Setting {
+	if setting := s.starter.Setting(); setting != nil {
+		return setting
+	}
+	return &Setting{}
+}
+
+// 模块是否未设置名称
+func (s *starterWrapper) unnamed() bool {
+	setting := s.starter.Setting()
+	return setting == nil || setting.starterName == ""
+}
+
 type starterWrappers []*starterWrapper
 
-// find 获取指定名称的Starter
+// 是否没有任何Starter
+func (s *starterWrappers) isEmpty() bool {
+	return s == nil || len(*s) == 0
+}
+
+// find 获取指定名称的Starter 未命名的模块可通过加载器分配的名称获取
 func (s *starterWrappers) find(starterName string) *starterWrapper {
 	for _, wrapper := range *s {
-		if wrapper.starter.Setting() != nil && wrapper.starter.Setting().starterName == starterName {
+		if wrapper.getStarterName() == starterName {
 			return wrapper
 		}
 	}
 	return nil
 }
 
-// 检查是否所有Setting均已配置
-func (s *starterWrappers) checkSetting() bool {
-	for _, v := range *s {
+// 未设置名称的组件位置
+func (s *starterWrappers) unnamedIndexes() []int {
+	indexes := make([]int, 0)
+	for i, v := range *s {
+		if v.unnamed() {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+// 未设置Setting的组件位置
+func (s *starterWrappers) missingSettings() []int {
+	indexes := make([]int, 0)
+	for i, v := range *s {
 		if v.starter.Setting() == nil {
-			return false
+			indexes = append(indexes, i)
 		}
 	}
-	return true
+	return indexes
 }
 
 // 未启动的组件名称
 func (s *starterWrappers) stoppedStarters() []string {
 	starterNames := make([]string, 0)
 	for _, v := range *s {
-		if v.status != StarterStatusStarted {
+		if v.getStatus() != StarterStatusStarted {
+			starterNames = append(starterNames, v.getStarterName())
+		}
+	}
+	return starterNames
+}
+
+// 重复的组件名称 忽略未命名的组件
+func (s *starterWrappers) duplicateNames() []string {
+	counts := make(map[string]int)
+	duplicates := make([]string, 0)
+	for _, v := range *s {
+		setting := v.starter.Setting()
+		if setting == nil || setting.starterName == "" {
+			continue
+		}
+		counts[setting.starterName]++
+		if counts[setting.starterName] == 2 {
+			duplicates = append(duplicates, setting.starterName)
+		}
+	}
+	return duplicates
+}
+
+// 是否所有启用的组件均已启动
+func (s *starterWrappers) allStarted() bool {
+	if s.isEmpty() {
+		return false
+	}
+	enabled := 0
+	for _, v := range *s {
+		if v.disabled() {
+			continue
+		}
+		enabled++
+		if v.getStatus() != StarterStatusStarted {
+			return false
+		}
+	}
+	return enabled > 0
+}
+
+// 是否存在已启动的组件
+func (s *starterWrappers) anyStarted() bool {
+	if s.isEmpty() {
+		return false
+	}
+	for _, v := range *s {
+		if v.getStatus() == StarterStatusStarted {
+			return true
+		}
+	}
+	return false
+}
+
+// 已启动的组件名称
+func (s *starterWrappers) startedStarters() []string {
+	starterNames := make([]string, 0)
+	for _, v := range *s {
+		if v.getStatus() == StarterStatusStarted {
 			starterNames = append(starterNames, v.getStarterName())
 		}
 	}
@@ -98,17 +368,50 @@ type Setting struct {
 	// 组件在初始化时执行指定的初始化方法 instance为各个组件的原始实例，由自模块控制，执行时机为执行Starter.Register成功后
 	initHandler func(instance interface{})
 
+	// 可返回异常的初始化方法 执行时机与initHandler相同，返回异常时视为模块启动失败
+	initErrorHandler func(instance interface{}) error
+
+	// 等待初始化方法的最大时间 0表示不限制
+	// 超时时默认视为模块启动失败，initTimeoutWarnOnly为true时仅输出警告日志，初始化方法将在后台继续执行
+	initMaxWaitTime     time.Duration
+	initTimeoutWarnOnly bool
+
 	// 卸载时优先级，权重越小，优先级越高 (适用于starterLoader执行按设置卸载模块)
-	// 注意，相同的优先级会导致不稳定排序出现不稳定的同优先级先后顺序
+	// 相同优先级的模块按注册顺序排列，如需保证优先级唯一可使用StarterLoader.ValidatePriorities检查
 	stopPriority uint
 
 	// 是否允许该模块异步卸载 (适用于starterLoader执行按设置卸载模块)
-	// 如果使用异步卸载，starterLoader将不等待该模块的卸载反馈直接执行后续操作
+	// 如果使用异步卸载，starterLoader将不等待该模块的卸载反馈直接执行后续梯队的卸载
 	stopAllowAsync bool
 
 	// 等待优雅停机的最大时间 (秒) (适用于starterLoader执行按设置卸载模块)
 	// StarterLoader 该超时不由Loader控制，因为无法感知真实Stop的状态，由具体模块实现
 	stopMaxWaitTime time.Duration
+
+	// 启动时依赖的模块名称 loader将保证依赖的模块先于该模块启动
+	dependsOn []string
+
+	// 启动时优先级，权重越小，优先级越高 (适用于starterLoader执行按设置启动模块)
+	// 未设置的模块将在所有设置了优先级的模块之后启动
+	startPriority    uint
+	startPrioritySet bool
+
+	// 启动失败时的最大重试次数及每次重试前的等待时间
+	maxStartRetries   int
+	startRetryBackoff time.Duration
+
+	// 是否启用该模块 返回false时loader将跳过该模块的启动
+	enabled func() bool
+
+	// 模块的标签 用于按标签对模块分组操作
+	labels map[string]string
+
+	// 被监管时的重启策略及健康检查 (适用于starterLoader执行Supervise)
+	restartPolicy RestartPolicy
+	healthCheck   HealthCheckFunc
+
+	// 所属的启动阶段 (适用于starterLoader执行按阶段启动/停止模块)
+	phase string
 }
 
 // NewSetting 创建一个模块设置
@@ -122,223 +425,1521 @@ func NewSetting(starterName string, stopPriority uint, stopAllowAsync bool, stop
 	}
 }
 
+// NewSettings 创建一个模块设置
+// Deprecated: 使用NewSetting
+func NewSettings(starterName string, stopPriority uint, stopAllowAsync bool, stopMaxWaitTime time.Duration, initHandler func(instance interface{})) *Setting {
+	return NewSetting(starterName, stopPriority, stopAllowAsync, stopMaxWaitTime, initHandler)
+}
+
+// 保证NewSettings与NewSetting的签名保持一致
+var _ = []func(string, uint, bool, time.Duration, func(interface{})) *Setting{NewSetting, NewSettings}
+
+// WithInitErrorHandler 设置可返回异常的初始化方法 在initHandler之后执行，返回异常时视为模块启动失败
+func (s *Setting) WithInitErrorHandler(initErrorHandler func(instance interface{}) error) *Setting {
+	s.initErrorHandler = initErrorHandler
+	return s
+}
+
+// WithInitMaxWaitTime 设置等待初始化方法(initHandler及initErrorHandler)的最大时间
+//
+//	warnOnly 超时时是否仅输出警告日志 否则视为模块启动失败
+//
+// 注意 超时后初始化方法无法被中断，将在后台继续执行至完成
+func (s *Setting) WithInitMaxWaitTime(initMaxWaitTime time.Duration, warnOnly bool) *Setting {
+	s.initMaxWaitTime = initMaxWaitTime
+	s.initTimeoutWarnOnly = warnOnly
+	return s
+}
+
+// WithDependsOn 设置启动时依赖的模块名称
+func (s *Setting) WithDependsOn(starterNames ...string) *Setting {
+	s.dependsOn = starterNames
+	return s
+}
+
+// WithStartPriority 设置启动时优先级
+func (s *Setting) WithStartPriority(startPriority uint) *Setting {
+	s.startPriority = startPriority
+	s.startPrioritySet = true
+	return s
+}
+
+// WithStartRetry 设置启动失败时的重试策略
+// 		maxStartRetries 最大重试次数 (不包含首次启动)
+// 		startRetryBackoff 每次重试前的等待时间
+func (s *Setting) WithStartRetry(maxStartRetries int, startRetryBackoff time.Duration) *Setting {
+	s.maxStartRetries = maxStartRetries
+	s.startRetryBackoff = startRetryBackoff
+	return s
+}
+
+// WithEnabled 设置是否启用该模块 enabled返回false时启动将跳过该模块，模块保持未启动状态
+func (s *Setting) WithEnabled(enabled func() bool) *Setting {
+	s.enabled = enabled
+	return s
+}
+
+// WithLabels 设置模块的标签 可通过StarterLoader按标签查询、启动或停止模块
+func (s *Setting) WithLabels(labels map[string]string) *Setting {
+	s.labels = labels
+	return s
+}
+
+// WithRestartPolicy 设置被监管时的重启策略
+func (s *Setting) WithRestartPolicy(restartPolicy RestartPolicy) *Setting {
+	s.restartPolicy = restartPolicy
+	return s
+}
+
+// WithHealthCheck 设置模块的健康检查 模块自身实现HealthChecker时优先使用模块的实现
+func (s *Setting) WithHealthCheck(healthCheck func(ctx context.Context) error) *Setting {
+	s.healthCheck = healthCheck
+	return s
+}
+
+// WithPhase 设置所属的启动阶段
+func (s *Setting) WithPhase(phase string) *Setting {
+	s.phase = phase
+	return s
+}
+
 // StopResult 模块停止卸载结果
 type StopResult struct {
 	// 卸载模块
 	StarterName string
+	// 模块在加载器模块列表中的位置 可据此将按优先级排序的结果对应回模块的注册顺序
+	Index int
+	// 异常信息 以模块名称为前缀，模块返回的原始异常可通过errors.Unwrap获取
+	Error error
+	// 模块是否已经完成停止
+	Stopped bool
+	// 是否优雅停机
+	Gracefully bool
+	// 停止耗时
+	Duration time.Duration
+	// 是否超时 因超过全局等待时间而未完成停止，或模块未能优雅停机且停止耗时达到模块的等待时间
+	// 后者的异常可通过errors.Is(err, ErrStopTimeout)判断，用于区分停止缓慢与停止异常
+	TimedOut bool
+	// 模块未处于启动状态而跳过停止 此时Error为nil
+	Skipped bool
+	// 未能优雅停机而调用了Forcible.ForceStop 强制停止成功时Stopped为true，Error仍保留优雅停机的异常
+	Forced bool
+	// 开启分阶段停止时每次停止尝试的结果 首次尝试未能优雅停机时将以完整的等待时间再次尝试
+	Attempts []StopAttempt
+}
+
+// StopAttempt 分阶段停止时一次停止尝试的结果
+type StopAttempt struct {
+	// 本次尝试的等待时间
+	MaxWaitTime time.Duration
 	// 异常信息
 	Error error
 	// 模块是否已经完成停止
 	Stopped bool
 	// 是否优雅停机
 	Gracefully bool
+	// 本次尝试的耗时
+	Duration time.Duration
+}
+
+// MarshalJSON 序列化停止结果 异常输出为异常信息，无异常时为null
+func (r StopResult) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		StarterName string        `json:"starterName"`
+		Index       int           `json:"index"`
+		Error       *string       `json:"error"`
+		Stopped     bool          `json:"stopped"`
+		Gracefully  bool          `json:"gracefully"`
+		Skipped     bool          `json:"skipped"`
+		TimedOut    bool          `json:"timedOut"`
+		Forced      bool          `json:"forced"`
+		Duration    string        `json:"duration"`
+		Attempts    []StopAttempt `json:"attempts"`
+	}{
+		StarterName: r.StarterName,
+		Index:       r.Index,
+		Error:       errorMessage(r.Error),
+		Stopped:     r.Stopped,
+		Gracefully:  r.Gracefully,
+		Skipped:     r.Skipped,
+		TimedOut:    r.TimedOut,
+		Forced:      r.Forced,
+		Duration:    r.Duration.String(),
+		Attempts:    r.Attempts,
+	})
+}
+
+// MarshalJSON 序列化停止尝试的结果 异常输出为异常信息，无异常时为null
+func (a StopAttempt) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		MaxWaitTime string  `json:"maxWaitTime"`
+		Error       *string `json:"error"`
+		Stopped     bool    `json:"stopped"`
+		Gracefully  bool    `json:"gracefully"`
+		Duration    string  `json:"duration"`
+	}{
+		MaxWaitTime: a.MaxWaitTime.String(),
+		Error:       errorMessage(a.Error),
+		Stopped:     a.Stopped,
+		Gracefully:  a.Gracefully,
+		Duration:    a.Duration.String(),
+	})
+}
+
+// 获取用于序列化的异常信息 无异常时为nil
+func errorMessage(err error) *string {
+	if err == nil {
+		return nil
+	}
+	message := err.Error()
+	return &message
+}
+
+// StartResult 模块启动结果
+type StartResult struct {
+	// 启动模块
+	StarterName string
+	// 模块在加载器模块列表中的位置
+	Index int
+	// 启动耗时 (包含初始化方法)
+	Duration time.Duration
+	// 异常信息
+	Error error
+	// 模块启动返回的实例
+	instance interface{}
+}
+
+// ErrStopTimeout 模块未能在等待时间内优雅停机 可通过errors.Is判断停止结果的异常是否为超时
+var ErrStopTimeout = errors.New("stop exceeding the maximum wait time")
+
+// StartAbortedError 启动过程因context取消或超时被中断
+type StartAbortedError struct {
+	// 中断时正在启动的模块
+	StarterName string
+	// 中断前已经启动完成的模块 调用方可据此决定是否回滚
+	Started []string
+	// context异常
+	Err error
+}
+
+func (e *StartAbortedError) Error() string {
+	if len(e.Started) == 0 {
+		return fmt.Sprintf("start %s aborted: %v", e.StarterName, e.Err)
+	}
+	return fmt.Sprintf("start %s aborted: %v, already started: %v", e.StarterName, e.Err, e.Started)
+}
+
+func (e *StartAbortedError) Unwrap() error {
+	return e.Err
 }
 
-// NewStarterLoader 创建一个模块加载器
+// 模块未能在等待时间内优雅停机且返回了异常 以模块名称为前缀
+// errors.Is可匹配ErrStopTimeout，errors.Unwrap返回模块的原始异常
+type stopTimeoutError struct {
+	starterName string
+	err         error
+}
+
+func (e *stopTimeoutError) Error() string {
+	return e.starterName + ": " + ErrStopTimeout.Error() + ": " + e.err.Error()
+}
+
+func (e *stopTimeoutError) Is(target error) bool {
+	return target == ErrStopTimeout
+}
+
+func (e *stopTimeoutError) Unwrap() error {
+	return e.err
+}
+
+// NewStarterLoader 创建一个模块加载器 每次调用均返回独立的加载器实例
 func NewStarterLoader(starters []Starter) *StarterLoader {
+	return NewStarterLoaderWith(WithStarters(starters...))
+}
+
+// SharedStarterLoader 获取全局共享的模块加载器
+// 注意 仅首次调用时传入的starters生效，后续调用将直接返回首次创建的加载器
+func SharedStarterLoader(starters []Starter) *StarterLoader {
 	once.Do(func() {
-		if len(starters) == 0 {
-			loader = &StarterLoader{}
-		} else {
-			if loader == nil {
-				wrappers := make([]*starterWrapper, len(starters))
-				for i, v := range starters {
-					wrappers[i] = &starterWrapper{
-						starter: v,
-					}
-				}
-				loader = &StarterLoader{
-					starters: (*starterWrappers)(&wrappers),
-				}
-			}
-		}
+		loader = NewStarterLoader(starters)
 	})
 	return loader
 }
 
-// AddStarter 添加一个模块
-func (s *StarterLoader) AddStarter(starter Starter) {
-	defer s.Mutex.Unlock()
-	s.Mutex.Lock()
-	if len(*s.starters) == 0 {
-		*s.starters = make([]*starterWrapper, 0)
+// Clone 创建一个独立的加载器 管理相同的模块实现及加载器设置(包括已注册的启动/停止回调)，所有模块均为未启动状态
+// 克隆的加载器不共享模块列表及事件订阅，之后注册的回调仅对注册的加载器生效，适用于相互隔离的测试
+func (s *StarterLoader) Clone() *StarterLoader {
+	starters := s.snapshot()
+	cloned := &StarterLoader{
+		logger:               s.logger,
+		defaultStopWait:      s.defaultStopWait,
+		strictNames:          s.strictNames,
+		requireNames:         s.requireNames,
+		requireSettings:      s.requireSettings,
+		unnamedSeq:           s.unnamedSeq,
+		phases:               append([]string(nil), s.phases...),
+		maxAsyncStops:        s.maxAsyncStops,
+		metricsRecorder:      s.metricsRecorder,
+		lifecycleTracer:      s.lifecycleTracer,
+		omitSkippedStops:     s.omitSkippedStops,
+		duplicateStartPolicy: s.duplicateStartPolicy,
+		softStopFraction:     s.softStopFraction,
+		progressSink:         s.progressSink,
+		lifecycleHooks:       s.lifecycleHooks.clone(),
 	}
-	v := append(*s.starters, &starterWrapper{
-		starter: starter,
-	})
-	s.starters = &v
+	if s.signals != nil {
+		// 克隆的加载器使用独立的信号来源 避免一次信号仅被其中一个加载器接收
+		cloned.signals = make(chan os.Signal, cap(s.signals))
+	}
+	if !starters.isEmpty() {
+		wrappers := starterWrappers(coll.SliceCollect(*starters, func(item *starterWrapper) *starterWrapper {
+			return &starterWrapper{starter: item.starter, index: item.index, generatedName: item.generatedName}
+		}))
+		cloned.starters = &wrappers
+	}
+	return cloned
 }
 
-// Start 启动所有未启动的模块 按starter加载顺序
-func (s *StarterLoader) Start() error {
+// Reset 将所有模块恢复为未启动状态并清除启动返回的实例 存在已启动或正在启动/停止的模块时返回异常
+func (s *StarterLoader) Reset() error {
 	defer s.Mutex.Unlock()
 	s.Mutex.Lock()
-	if len(*s.starters) == 0 {
-		return errors.New("miss starters")
+	if s.starters.isEmpty() {
+		return nil
 	}
 	for _, wrapper := range *s.starters {
-		if err := start(wrapper); err != nil {
-			return err
+		if !wrapper.startable() {
+			return errors.New("starter " + wrapper.getStarterName() + " is " + wrapper.getStatus().String() + ", stop it before reset")
 		}
 	}
+	s.resetShutdown()
+	for _, wrapper := range *s.starters {
+		wrapper.setStatus(StarterStatusNotStarted)
+		wrapper.instance = nil
+		wrapper.startSeq = 0
+		wrapper.setLastError(nil)
+	}
 	return nil
 }
 
-// StartStarter 启动指定未启动的模块
-func (s *StarterLoader) StartStarter(starterName string) error {
+// AddStarter 添加一个模块 添加的模块不会自动启动，可通过StartNewStarters启动新添加的模块
+// 正在进行启动/停止时将阻塞直到其完成，因此添加的模块不会加入正在进行的启动
+// 模块名称与已有模块重复时，严格模式下不添加该模块并返回异常，否则仅输出警告日志
+func (s *StarterLoader) AddStarter(starter Starter) error {
 	defer s.Mutex.Unlock()
 	s.Mutex.Lock()
-	if len(*s.starters) == 0 {
-		return errors.New("no starter")
+	var appended starterWrappers
+	if s.starters != nil {
+		appended = append(appended, *s.starters...)
 	}
-	wrapper := s.starters.find(starterName)
-	if wrapper == nil {
-		return errors.New("unknown starterName: " + starterName)
+	wrapper := &starterWrapper{starter: starter, index: len(appended)}
+	appended = append(appended, wrapper)
+	if err := s.checkNames(&appended); err != nil {
+		return err
 	}
-	return start(wrapper)
+	s.assignName(wrapper)
+	defer s.startersMutex.Unlock()
+	s.startersMutex.Lock()
+	s.starters = &appended
+	return nil
 }
 
-// StopBySetting 按照卸载配置停止所有模块
-func (s *StarterLoader) StopBySetting(allMaxWaitTime ...time.Duration) ([]*StopResult, error) {
+// InsertStarter 在指定位置插入模块 index为插入后首个模块所在的位置，取值范围为[0, 模块数]
+// 插入的模块不会自动启动，模块名称与已有模块重复时不插入并返回异常
+func (s *StarterLoader) InsertStarter(index int, starters ...Starter) error {
 	defer s.Mutex.Unlock()
 	s.Mutex.Lock()
-	if len(*s.starters) == 0 {
-		return nil, errors.New("no starter")
+	var existing starterWrappers
+	if s.starters != nil {
+		existing = *s.starters
 	}
-	if !s.starters.checkSetting() {
-		return nil, errors.New("some starter has no setting")
+	if index < 0 || index > len(existing) {
+		return fmt.Errorf("index %d out of range [0, %d]", index, len(existing))
 	}
-	copied := coll.SliceCollect(*s.starters, func(item *starterWrapper) *starterWrapper {
-		return item
-	})
-	coll.SliceSort(copied, func(e *starterWrapper) int {
-		return int(e.starter.Setting().stopPriority)
+	added := coll.SliceCollect(starters, func(item Starter) *starterWrapper {
+		return &starterWrapper{starter: item}
 	})
-	stopResult := make([]*StopResult, 0)
-	var wg sync.WaitGroup
-	wg.Add(len(*s.starters))
-	var mu sync.Mutex
-	go func() {
-		coll.SliceForeachAll(copied, func(wrapper *starterWrapper) {
-			setting := wrapper.starter.Setting()
-			if !setting.stopAllowAsync {
-				result := stop(wrapper, setting.stopMaxWaitTime)
-				mu.Lock()
-				stopResult = append(stopResult, result)
-				wg.Done()
-				mu.Unlock()
-			} else {
-				go func(starterWrapper *starterWrapper) {
-					defer wg.Done()
-					result := stop(starterWrapper, starterWrapper.starter.Setting().stopMaxWaitTime)
-					mu.Lock()
-					stopResult = append(stopResult, result)
-					mu.Unlock()
-				}(wrapper)
-			}
-		})
-	}()
-	if len(allMaxWaitTime) > 0 {
-		allStopDone := make(chan struct{})
-		go func() {
-			wg.Wait()
-			close(allStopDone)
-		}()
-		select {
-		case <-allStopDone:
-			return stopResult, nil
-		case <-time.After(allMaxWaitTime[0]):
-			return stopResult, errors.New("stop the module exceeding the maximum wait time")
-		}
-	} else {
-		wg.Wait()
+	inserted := make(starterWrappers, 0, len(existing)+len(starters))
+	inserted = append(inserted, existing[:index]...)
+	inserted = append(inserted, added...)
+	inserted = append(inserted, existing[index:]...)
+	if duplicates := inserted.duplicateNames(); len(duplicates) > 0 {
+		return fmt.Errorf("duplicate starterName: %v", duplicates)
 	}
-	return stopResult, nil
-}
-
-// StoppedStarters 未启动的模块名
-func (s *StarterLoader) StoppedStarters() []string {
-	defer s.Mutex.Unlock()
-	s.Mutex.Lock()
-	if len(*s.starters) == 0 {
-		return nil
+	if err := s.checkNames(&inserted); err != nil {
+		return err
+	}
+	for i, wrapper := range inserted {
+		wrapper.index = i
 	}
-	return s.starters.stoppedStarters()
+	for _, wrapper := range added {
+		s.assignName(wrapper)
+	}
+	defer s.startersMutex.Unlock()
+	s.startersMutex.Lock()
+	s.starters = &inserted
+	return nil
 }
 
-// Stop 按starter加载顺序停止所有模块 忽略卸载配置
-func (s *StarterLoader) Stop(maxWaitTime time.Duration) ([]*StopResult, error) {
+// RemoveStarter 移除指定的模块 已启动的模块需要先停止才能移除
+func (s *StarterLoader) RemoveStarter(starterName string) error {
 	defer s.Mutex.Unlock()
 	s.Mutex.Lock()
-	if len(*s.starters) == 0 {
-		return nil, errors.New("no starter")
+	if s.starters.isEmpty() {
+		return errors.New("no starter")
 	}
-	stopResult := make([]*StopResult, 0)
-	for _, wrapper := range *s.starters {
-		stopResult = append(stopResult, stop(wrapper, maxWaitTime))
+	wrapper := s.starters.find(starterName)
+	if wrapper == nil {
+		return errors.New("unknown starterName: " + starterName)
 	}
-	return stopResult, nil
+	if !wrapper.startable() {
+		return errors.New("starter " + starterName + " is " + wrapper.getStatus().String() + ", stop it before remove")
+	}
+	remained := starterWrappers(coll.SliceFilter(*s.starters, func(item *starterWrapper) bool {
+		return item != wrapper
+	}))
+	for i, item := range remained {
+		item.index = i
+	}
+	defer s.startersMutex.Unlock()
+	s.startersMutex.Lock()
+	s.starters = &remained
+	return nil
 }
 
-// StopStarter 停止指定的模块
-func (s *StarterLoader) StopStarter(starterName string, maxWaitTime time.Duration) (*StopResult, error) {
+// ReplaceStarter 在原位置替换指定的模块 新模块的名称需与被替换的模块一致
+// 已启动的模块默认返回异常，传入stopMaxWaitTime时将先停止该模块再替换，模块未能成功停止时不替换
+func (s *StarterLoader) ReplaceStarter(starterName string, newStarter Starter, stopMaxWaitTime ...time.Duration) error {
 	defer s.Mutex.Unlock()
 	s.Mutex.Lock()
-	if len(*s.starters) == 0 {
-		return nil, errors.New("no starter")
+	if s.starters.isEmpty() {
+		return errors.New("no starter")
+	}
+	if newStarter == nil || newStarter.Setting() == nil {
+		return errors.New("new starter has no setting")
+	}
+	if name := newStarter.Setting().starterName; name != starterName {
+		return errors.New("starterName mismatch: " + starterName + " replaced by " + name)
 	}
 	wrapper := s.starters.find(starterName)
 	if wrapper == nil {
-		return nil, errors.New("unknown starterName: " + starterName)
+		return errors.New("unknown starterName: " + starterName)
 	}
-	return stop(wrapper, maxWaitTime), nil
-}
-
-// 启动指定的模块 如果已启动则忽略
-func start(wrapper *starterWrapper) error {
-	if wrapper.status != StarterStatusStarted {
-		starter := wrapper.starter
-		setting := starter.Setting()
-		starterName := wrapper.getStarterName()
-		current := time.Now()
-		logger.Logrus().Traceln(starterName, "starting now...")
-		instance, err := starter.Start()
-		if err != nil {
-			logger.Logrus().WithError(err).Errorln(starterName, "start failed with error:", err)
-			return err
+	if wrapper.getStatus() == StarterStatusStarted && len(stopMaxWaitTime) > 0 {
+		if result := s.stop(context.Background(), wrapper, stopMaxWaitTime[0]); !result.Stopped {
+			return errors.New("replace " + starterName + " failed: module not stopped")
 		}
-		if setting != nil && setting.initHandler != nil {
-			// 执行初始化方法
-			setting.initHandler(instance)
+	}
+	if !wrapper.startable() {
+		return errors.New("starter " + starterName + " is " + wrapper.getStatus().String() + ", stop it before replace")
+	}
+	replaced := starterWrappers(coll.SliceCollect(*s.starters, func(item *starterWrapper) *starterWrapper {
+		if item == wrapper {
+			return &starterWrapper{starter: newStarter, index: wrapper.index}
 		}
-		logger.Logrus().Traceln(starterName, "started successful cost:", time.Since(current))
-		wrapper.status = StarterStatusStarted
+		return item
+	}))
+	defer s.startersMutex.Unlock()
+	s.startersMutex.Lock()
+	s.starters = &replaced
+	return nil
+}
+
+// 检查是否存在未设置Setting的模块 开启WithRequireSettings时返回异常，否则输出警告日志并使用默认设置
+func (s *StarterLoader) checkSettings(wrappers *starterWrappers) error {
+	missing := wrappers.missingSettings()
+	if len(missing) == 0 {
+		return nil
 	}
+	if s.requireSettings {
+		return fmt.Errorf("some starter has no setting, index: %v", missing)
+	}
+	s.log().Warnln("some starter has no setting, index:", missing, "use default setting")
 	return nil
 }
 
-// 停止指定的模块
-func stop(wrapper *starterWrapper, maxWaitTime time.Duration) *StopResult {
-	starterName := wrapper.getStarterName()
-	if wrapper.status != StarterStatusStarted {
-		return &StopResult{StarterName: starterName, Error: errors.New("not started")}
+// 为未设置名称的模块分配唯一的名称 如unnamed-1
+// 仅在模块通过校验并加入模块列表时调用，被拒绝添加的模块不占用序号
+func (s *StarterLoader) assignName(wrapper *starterWrapper) {
+	if wrapper.unnamed() && wrapper.generatedName == "" {
+		s.unnamedSeq++
+		wrapper.generatedName = fmt.Sprintf("unnamed-%d", s.unnamedSeq)
 	}
-	starter := wrapper.starter
-	current := time.Now()
-	logger.Logrus().Traceln(starterName, "stopping now...")
-	gracefully, stopped, err := starter.Stop(maxWaitTime)
-	if err != nil {
-		logger.Logrus().WithError(err).Errorln(starterName, "stop failed with error", err)
-	} else {
-		logger.Logrus().Traceln(starterName, "stopped successful cost:", time.Since(current))
+}
+
+// 检查模块名称是否重复或未设置 严格模式下返回异常，否则仅输出警告日志
+func (s *StarterLoader) checkNames(wrappers *starterWrappers) error {
+	if err := s.checkSettings(wrappers); err != nil {
+		return err
 	}
-	if stopped {
-		wrapper.status = StarterStatusStopped
+	if s.requireNames {
+		if unnamed := wrappers.unnamedIndexes(); len(unnamed) > 0 {
+			return fmt.Errorf("starterName is required, unnamed starter at index: %v", unnamed)
+		}
 	}
-	return &StopResult{
-		StarterName: starterName,
-		Error:       err,
-		Gracefully:  gracefully,
-		Stopped:     stopped,
+	duplicates := wrappers.duplicateNames()
+	if len(duplicates) == 0 {
+		return nil
+	}
+	if s.strictNames {
+		return fmt.Errorf("duplicate starterName: %v", duplicates)
+	}
+	s.log().Warnln("duplicate starterName:", duplicates, "only the first one can be found by name")
+	return nil
+}
+
+// Start 启动所有未启动的模块 按starter加载顺序
+// 如果模块设置了依赖，将保证依赖的模块先启动，依赖不存在或出现循环依赖时不启动任何模块并返回异常
+func (s *StarterLoader) Start() error {
+	return s.StartWithContext(context.Background())
+}
+
+// StartDetailed 启动所有未启动的模块 按starter加载顺序 并返回本次执行启动的模块结果
+func (s *StarterLoader) StartDetailed() ([]*StartResult, error) {
+	defer s.Mutex.Unlock()
+	s.Mutex.Lock()
+	if s.starters.isEmpty() {
+		return nil, errors.New("miss starters")
+	}
+	return s.startAll(context.Background(), *s.starters)
+}
+
+// StartWithContext 启动所有未启动的模块 按starter加载顺序
+// 当ctx被取消或超时，将放弃后续模块的启动并返回 *StartAbortedError
+// 注意 中断时正在启动的模块将在后台继续完成启动，完成后其状态仍会被记录
+func (s *StarterLoader) StartWithContext(ctx context.Context) error {
+	defer s.Mutex.Unlock()
+	s.Mutex.Lock()
+	if s.starters.isEmpty() {
+		return errors.New("miss starters")
+	}
+	_, err := s.startAll(ctx, *s.starters)
+	return err
+}
+
+// StartNewStarters 按依赖顺序启动所有从未启动过的模块 (如启动后通过AddStarter添加的模块)
+// 与Start不同，已停止的模块不会被再次启动
+func (s *StarterLoader) StartNewStarters() error {
+	defer s.Mutex.Unlock()
+	s.Mutex.Lock()
+	if s.starters.isEmpty() {
+		return errors.New("miss starters")
+	}
+	ordered, err := dependencyOrder(*s.starters)
+	if err != nil {
+		return err
+	}
+	for _, wrapper := range ordered {
+		if wrapper.getStatus() != StarterStatusNotStarted {
+			continue
+		}
+		if err = s.start(wrapper); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StartAsync 在后台执行Start 启动完成后通过返回的channel发送启动异常(成功时为nil)并关闭channel
+// 后台的启动与其他启动/停止操作仍然互斥执行
+func (s *StarterLoader) StartAsync() <-chan error {
+	done := make(chan error, 1)
+	go func() {
+		defer close(done)
+		done <- s.Start()
+	}()
+	return done
+}
+
+// StartWithTimeout 在指定时间内启动所有未启动的模块 按starter加载顺序
+// 超时后放弃后续模块的启动并返回 *StartAbortedError (errors.Is(err, context.DeadlineExceeded)为true)
+// 异常中包含超时时正在启动的模块及已启动的模块，调用方可据此决定是否回滚
+func (s *StarterLoader) StartWithTimeout(d time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+	return s.StartWithContext(ctx)
+}
+
+// StartContinueOnError 尝试启动所有未启动的模块 某个模块启动失败不影响后续模块的启动
+// 返回本次尝试启动的模块结果 启动失败的模块Error不为nil
+func (s *StarterLoader) StartContinueOnError() []*StartResult {
+	defer s.Mutex.Unlock()
+	s.Mutex.Lock()
+	if s.starters.isEmpty() {
+		return nil
+	}
+	ordered, err := dependencyOrder(*s.starters)
+	if err != nil {
+		s.log().WithError(err).Errorln("resolve dependency failed, start by registration order")
+		ordered = *s.starters
+	}
+	startResult := make([]*StartResult, 0)
+	for _, wrapper := range ordered {
+		if !wrapper.startable() || s.skipDisabled(wrapper) {
+			continue
+		}
+		previous := s.markStarting(wrapper)
+		result := s.launch(wrapper)
+		wrapper.finishStart(result, previous)
+		startResult = append(startResult, result)
+	}
+	return startResult
+}
+
+// StartParallel 按依赖层级启动所有未启动的模块 同一层级内互不依赖的模块将并发启动
+// 		maxConcurrency 同时启动的最大模块数 小于等于0时不限制
+// 任一模块启动失败后，将不再启动尚未开始的模块，待当前已开始的模块完成后返回异常
+func (s *StarterLoader) StartParallel(maxConcurrency int) error {
+	defer s.Mutex.Unlock()
+	s.Mutex.Lock()
+	if s.starters.isEmpty() {
+		return errors.New("miss starters")
+	}
+	if err := s.checkNames(s.starters); err != nil {
+		return err
+	}
+	levels, err := dependencyLevels(*s.starters)
+	if err != nil {
+		return err
+	}
+	for _, level := range levels {
+		pending := coll.SliceFilter(level, func(item *starterWrapper) bool {
+			return item.startable() && !s.skipDisabled(item)
+		})
+		if len(pending) == 0 {
+			continue
+		}
+		limit := maxConcurrency
+		if limit <= 0 || limit > len(pending) {
+			limit = len(pending)
+		}
+		semaphore := make(chan struct{}, limit)
+		results := make([]*StartResult, len(pending))
+		previous := make([]StarterStatus, len(pending))
+		var failed atomic.Bool
+		var wg sync.WaitGroup
+		for i, wrapper := range pending {
+			semaphore <- struct{}{}
+			if failed.Load() {
+				<-semaphore
+				break
+			}
+			wg.Add(1)
+			previous[i] = s.markStarting(wrapper)
+			go func(index int, wrapper *starterWrapper) {
+				defer func() {
+					<-semaphore
+					wg.Done()
+				}()
+				results[index] = s.launch(wrapper)
+				if results[index].Error != nil {
+					failed.Store(true)
+				}
+			}(i, wrapper)
+		}
+		wg.Wait()
+		var errs []error
+		for i, result := range results {
+			if result == nil {
+				continue
+			}
+			if result.Error != nil {
+				errs = append(errs, result.Error)
+			}
+			pending[i].finishStart(result, previous[i])
+		}
+		if len(errs) > 0 {
+			return errors.Join(errs...)
+		}
+	}
+	return nil
+}
+
+// StartWithRollback 启动所有未启动的模块 按starter加载顺序
+// 当某个模块启动失败时，将按启动的相反顺序停止本次已启动的模块 (使用模块设置的stopMaxWaitTime)
+// 回滚为尽力而为，回滚中的异常将通过errors.Join附加到返回的异常中
+// 注意 此为可选行为，Start启动失败时仍保持已启动模块的运行状态
+func (s *StarterLoader) StartWithRollback() error {
+	defer s.Mutex.Unlock()
+	s.Mutex.Lock()
+	if s.starters.isEmpty() {
+		return errors.New("miss starters")
+	}
+	ordered, err := dependencyOrder(*s.starters)
+	if err != nil {
+		return err
+	}
+	pending := coll.SliceFilter(ordered, func(item *starterWrapper) bool {
+		return item.startable()
+	})
+	result, err := s.startAll(context.Background(), *s.starters)
+	if err == nil {
+		return nil
+	}
+	failed := "unknown"
+	if len(result) > 0 {
+		failed = result[len(result)-1].StarterName
+	}
+	rolledBack := make([]string, 0)
+	errs := make([]error, 0)
+	for i := len(pending) - 1; i >= 0; i-- {
+		wrapper := pending[i]
+		if wrapper.getStatus() != StarterStatusStarted {
+			continue
+		}
+		var maxWaitTime time.Duration
+		if setting := wrapper.starter.Setting(); setting != nil {
+			maxWaitTime = setting.stopMaxWaitTime
+		}
+		stopResult := s.stop(context.Background(), wrapper, maxWaitTime)
+		rolledBack = append(rolledBack, stopResult.StarterName)
+		if stopResult.Error != nil {
+			errs = append(errs, fmt.Errorf("rollback failed: %w", stopResult.Error))
+		}
+	}
+	return errors.Join(append([]error{fmt.Errorf("start %s failed, rolled back %v: %w", failed, rolledBack, err)}, errs...)...)
+}
+
+// StartBySetting 按照启动配置启动所有未启动的模块
+// 设置了startPriority的模块按权重升序先启动，未设置的模块随后按加载顺序启动，依赖的模块紧邻在依赖它的模块之前启动
+func (s *StarterLoader) StartBySetting() error {
+	defer s.Mutex.Unlock()
+	s.Mutex.Lock()
+	if s.starters.isEmpty() {
+		return errors.New("miss starters")
+	}
+	_, err := s.startAll(context.Background(), sortByStartPriority(*s.starters))
+	return err
+}
+
+// 按startPriority升序排列模块 未设置优先级的模块排在最后并保持原有顺序
+func sortByStartPriority(wrappers []*starterWrapper) []*starterWrapper {
+	copied := coll.SliceCollect(wrappers, func(item *starterWrapper) *starterWrapper {
+		return item
+	})
+	sort.SliceStable(copied, func(i, j int) bool {
+		left, right := copied[i].setting(), copied[j].setting()
+		if left.startPrioritySet != right.startPrioritySet {
+			return left.startPrioritySet
+		}
+		return left.startPriority < right.startPriority
+	})
+	return copied
+}
+
+// 按stopPriority排序的模块副本 相同优先级的模块保持注册顺序
+func sortByStopPriority(wrappers []*starterWrapper) []*starterWrapper {
+	copied := coll.SliceCollect(wrappers, func(item *starterWrapper) *starterWrapper {
+		return item
+	})
+	sort.SliceStable(copied, func(i, j int) bool {
+		return copied[i].setting().stopPriority < copied[j].setting().stopPriority
+	})
+	return copied
+}
+
+// ValidatePriorities 检查是否存在stopPriority相同的模块 存在时返回的异常中列出所有冲突的优先级及模块名
+func (s *StarterLoader) ValidatePriorities() error {
+	starters := s.snapshot()
+	if starters.isEmpty() {
+		return errors.New("no starter")
+	}
+	collisions := make([]string, 0)
+	sorted := sortByStopPriority(*starters)
+	for begin := 0; begin < len(sorted); {
+		priority := sorted[begin].setting().stopPriority
+		end := begin
+		for end < len(sorted) && sorted[end].setting().stopPriority == priority {
+			end++
+		}
+		if end-begin > 1 {
+			names := coll.SliceCollect(sorted[begin:end], func(item *starterWrapper) string {
+				return item.getStarterName()
+			})
+			collisions = append(collisions, fmt.Sprintf("%d: %s", priority, strings.Join(names, ", ")))
+		}
+		begin = end
+	}
+	if len(collisions) > 0 {
+		return errors.New("duplicate stopPriority " + strings.Join(collisions, "; "))
+	}
+	return nil
+}
+
+// 按wrappers顺序启动所有未启动的模块 调用方需持有锁
+func (s *StarterLoader) startAll(ctx context.Context, wrappers []*starterWrapper) ([]*StartResult, error) {
+	if len(wrappers) == 0 {
+		return nil, errors.New("miss starters")
+	}
+	if err := s.checkNames(s.starters); err != nil {
+		return nil, err
+	}
+	ordered, err := dependencyOrder(wrappers)
+	if err != nil {
+		return nil, err
+	}
+	startResult := make([]*StartResult, 0)
+	started := make([]string, 0)
+	for _, wrapper := range ordered {
+		if wrapper.getStatus() == StarterStatusStarted {
+			started = append(started, wrapper.getStarterName())
+			continue
+		}
+		if !wrapper.startable() {
+			// 上一次被中断的启动仍在进行中
+			continue
+		}
+		if s.skipDisabled(wrapper) {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return startResult, &StartAbortedError{StarterName: wrapper.getStarterName(), Started: started, Err: err}
+		}
+		previous := s.markStarting(wrapper)
+		done := make(chan *StartResult, 1)
+		go func(wrapper *starterWrapper) {
+			done <- s.launch(wrapper)
+		}(wrapper)
+		select {
+		case result := <-done:
+			startResult = append(startResult, result)
+			wrapper.finishStart(result, previous)
+			if result.Error != nil {
+				return startResult, result.Error
+			}
+			started = append(started, wrapper.getStarterName())
+		case <-ctx.Done():
+			go func(wrapper *starterWrapper) {
+				result := <-done
+				s.Mutex.Lock()
+				wrapper.finishStart(result, previous)
+				s.Mutex.Unlock()
+			}(wrapper)
+			return startResult, &StartAbortedError{StarterName: wrapper.getStarterName(), Started: started, Err: ctx.Err()}
+		}
+	}
+	return startResult, nil
+}
+
+// StartStarter 启动指定未启动的模块
+// 模块已启动时默认忽略，设置了DuplicateStartError策略时返回异常
+func (s *StarterLoader) StartStarter(starterName string) error {
+	defer s.Mutex.Unlock()
+	s.Mutex.Lock()
+	return s.startStarter(starterName)
+}
+
+// 启动指定未启动的模块 调用方需持有锁
+func (s *StarterLoader) startStarter(starterName string) error {
+	if s.starters.isEmpty() {
+		return errors.New("no starter")
+	}
+	wrapper := s.starters.find(starterName)
+	if wrapper == nil {
+		return errors.New("unknown starterName: " + starterName)
+	}
+	if wrapper.getStatus() == StarterStatusStarted && s.duplicateStartPolicy == DuplicateStartError {
+		return errors.New("starter " + starterName + " already started")
+	}
+	return s.start(wrapper)
+}
+
+// StartStarters 按传入顺序启动多个指定的模块 任一模块名不存在时不启动任何模块
+// 某个模块启动失败时不再启动后续模块，返回的异常中包含启动失败的模块名，已启动的模块保持运行
+func (s *StarterLoader) StartStarters(starterNames ...string) error {
+	defer s.Mutex.Unlock()
+	s.Mutex.Lock()
+	if s.starters.isEmpty() {
+		return errors.New("no starter")
+	}
+	wrappers := make([]*starterWrapper, len(starterNames))
+	for i, starterName := range starterNames {
+		wrappers[i] = s.starters.find(starterName)
+		if wrappers[i] == nil {
+			return errors.New("unknown starterName: " + starterName)
+		}
+	}
+	for i, wrapper := range wrappers {
+		if wrapper.getStatus() == StarterStatusStarted && s.duplicateStartPolicy == DuplicateStartError {
+			return errors.New("starter " + starterNames[i] + " already started")
+		}
+		if err := s.start(wrapper); err != nil {
+			return fmt.Errorf("start %s failed: %w", starterNames[i], err)
+		}
+	}
+	return nil
+}
+
+// StopBySetting 按照卸载配置停止所有模块 未启动的模块不执行停止，结果标记为Skipped (可通过WithOmitSkippedStops省略)
+// 相同stopPriority的模块组成一个梯队并发停止，当前梯队中非异步停止的模块完成后才开始停止下一梯队
+// 设置了stopAllowAsync的模块不阻塞后续梯队，但被依赖的模块仍将等待依赖它的异步模块完成停止
+// 设置了依赖的模块，被依赖的模块总是在依赖它的模块之后的梯队停止，与stopPriority冲突时以依赖关系为准
+// 		allMaxWaitTime 全局等待时间 超时后实现了ContextStarter的模块将收到取消信号，尚未开始停止的模块不再停止
+// 		未在全局等待时间内完成的模块将以TimedOut标记返回，返回的异常中列出这些模块的名称
+func (s *StarterLoader) StopBySetting(allMaxWaitTime ...time.Duration) ([]*StopResult, error) {
+	return s.StopBySettingWithOverride(nil, allMaxWaitTime...)
+}
+
+// StopBySettingWithOverride 按照卸载配置停止所有模块 与StopBySetting的停止行为一致
+// 		override 按模块名称覆盖模块设置的stopMaxWaitTime 未包含的模块使用模块设置的等待时间，包含不存在的模块名时不停止任何模块
+// 适用于在故障处理时临时延长指定模块的优雅停机时间
+func (s *StarterLoader) StopBySettingWithOverride(override map[string]time.Duration, allMaxWaitTime ...time.Duration) ([]*StopResult, error) {
+	defer s.Mutex.Unlock()
+	s.Mutex.Lock()
+	if s.starters.isEmpty() {
+		s.beginShutdown()
+		return nil, errors.New("no starter")
+	}
+	for starterName := range override {
+		if s.starters.find(starterName) == nil {
+			return nil, errors.New("unknown starterName: " + starterName)
+		}
+	}
+	s.beginShutdown()
+	copied, priorities := s.stopOrder(*s.starters)
+	ctx, cancel := stopContext(allMaxWaitTime...)
+	defer cancel()
+	// 按排序后的位置写入结果，保证返回结果按stopPriority有序
+	stopResult := make([]*StopResult, len(copied))
+	done := make([]bool, len(copied))
+	var mu sync.Mutex
+	allStopDone := s.stopByTiers(ctx, copied, priorities, override, func(index int, result *StopResult) {
+		mu.Lock()
+		stopResult[index] = result
+		done[index] = true
+		mu.Unlock()
+	})
+	select {
+	case <-allStopDone:
+		return s.omitSkipped(stopResult), nil
+	case <-ctx.Done():
+		// 未在全局等待时间内完成的模块标记为超时
+		mu.Lock()
+		defer mu.Unlock()
+		returned := make([]*StopResult, len(stopResult))
+		unfinished := make([]string, 0)
+		for i, result := range stopResult {
+			if !done[i] {
+				result = &StopResult{StarterName: copied[i].getStarterName(), Index: copied[i].index, Error: fmt.Errorf("%s: %w", copied[i].getStarterName(), ctx.Err()), TimedOut: true}
+				unfinished = append(unfinished, result.StarterName)
+			}
+			returned[i] = result
+		}
+		return s.omitSkipped(returned), fmt.Errorf("stop the module exceeding the maximum wait time, unfinished: %v", unfinished)
+	}
+}
+
+// StopBySettingStream 按照卸载配置停止所有模块 与StopBySetting的停止行为一致
+// 每个模块完成停止时即通过返回的channel发送其结果，所有模块完成或超过全局等待时间后关闭channel
+// 超过全局等待时间时，尚未完成的模块将以TimedOut标记发送 channel关闭前其他启动/停止操作将等待
+func (s *StarterLoader) StopBySettingStream(allMaxWaitTime ...time.Duration) (<-chan *StopResult, error) {
+	s.Mutex.Lock()
+	s.beginShutdown()
+	if s.starters.isEmpty() {
+		s.Mutex.Unlock()
+		return nil, errors.New("no starter")
+	}
+	copied, priorities := s.stopOrder(*s.starters)
+	ctx, cancel := stopContext(allMaxWaitTime...)
+	// 缓冲区可容纳所有模块的结果 发送不会阻塞停止流程
+	stream := make(chan *StopResult, len(copied))
+	reported := make([]bool, len(copied))
+	closed := false
+	var mu sync.Mutex
+	allStopDone := s.stopByTiers(ctx, copied, priorities, nil, func(index int, result *StopResult) {
+		mu.Lock()
+		defer mu.Unlock()
+		if closed {
+			return
+		}
+		reported[index] = true
+		if result.Skipped && s.omitSkippedStops {
+			return
+		}
+		stream <- result
+	})
+	go func() {
+		defer s.Mutex.Unlock()
+		defer cancel()
+		select {
+		case <-allStopDone:
+		case <-ctx.Done():
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		for i, done := range reported {
+			if !done {
+				stream <- &StopResult{StarterName: copied[i].getStarterName(), Index: copied[i].index, Error: fmt.Errorf("%s: %w", copied[i].getStarterName(), ctx.Err()), TimedOut: true}
+			}
+		}
+		closed = true
+		close(stream)
+	}()
+	return stream, nil
+}
+
+// 开启WithOmitSkippedStops时移除因未启动而跳过的停止结果
+func (s *StarterLoader) omitSkipped(stopResult []*StopResult) []*StopResult {
+	if !s.omitSkippedStops {
+		return stopResult
+	}
+	return coll.SliceFilter(stopResult, func(item *StopResult) bool {
+		return !item.Skipped
+	})
+}
+
+// 按全局等待时间创建停止使用的context 未设置时不限制
+func stopContext(allMaxWaitTime ...time.Duration) (context.Context, context.CancelFunc) {
+	if len(allMaxWaitTime) > 0 {
+		return context.WithTimeout(context.Background(), allMaxWaitTime[0])
+	}
+	return context.WithCancel(context.Background())
+}
+
+// 按停止优先级梯队停止已排序的模块 priorities为各模块生效的停止优先级 每个模块完成停止时以其位置回调report
+// override中包含的模块使用覆盖的等待时间 已超过全局等待时间而未执行停止的模块不回调 所有模块处理完成后关闭返回的channel
+func (s *StarterLoader) stopByTiers(ctx context.Context, sorted []*starterWrapper, priorities []uint, override map[string]time.Duration, report func(index int, result *StopResult)) <-chan struct{} {
+	var wg sync.WaitGroup
+	wg.Add(len(sorted))
+	var semaphore chan struct{}
+	if s.maxAsyncStops > 0 {
+		semaphore = make(chan struct{}, s.maxAsyncStops)
+	}
+	// 各模块完成停止(或放弃停止)时关闭 用于被依赖的模块等待异步停止的依赖方
+	finished := make([]chan struct{}, len(sorted))
+	for i := range finished {
+		finished[i] = make(chan struct{})
+	}
+	dependents := dependentIndexes(sorted)
+	// 相同stopPriority的模块为同一梯队并发停止，前一梯队中非异步卸载的模块全部完成后才开始下一梯队
+	go func() {
+		for begin := 0; begin < len(sorted); {
+			end := begin
+			for end < len(sorted) && priorities[end] == priorities[begin] {
+				end++
+			}
+			var tier sync.WaitGroup
+			for i := begin; i < end; i++ {
+				async := sorted[i].setting().stopAllowAsync
+				acquired := false
+				if !async {
+					tier.Add(1)
+				} else if semaphore != nil {
+					// 达到异步停止的并发上限时等待 已超过全局等待时间则不再等待
+					select {
+					case semaphore <- struct{}{}:
+						acquired = true
+					case <-ctx.Done():
+					}
+				}
+				go func(index, tierBegin int, starterWrapper *starterWrapper, async, acquired bool) {
+					defer wg.Done()
+					defer close(finished[index])
+					if !async {
+						defer tier.Done()
+					}
+					if acquired {
+						defer func() { <-semaphore }()
+					}
+					defer func() {
+						if r := recover(); r != nil {
+							report(index, &StopResult{StarterName: starterWrapper.getStarterName(), Index: starterWrapper.index, Error: fmt.Errorf("%s: stop panic: %v", starterWrapper.getStarterName(), r)})
+						}
+					}()
+					// 前序梯队中依赖该模块的模块(包括异步停止的模块)完成后才停止该模块
+					for _, dependent := range dependents[index] {
+						if dependent >= tierBegin {
+							continue
+						}
+						select {
+						case <-finished[dependent]:
+						case <-ctx.Done():
+						}
+					}
+					if ctx.Err() != nil {
+						// 已超过全局等待时间 不再执行停止
+						return
+					}
+					maxWaitTime, ok := override[starterWrapper.getStarterName()]
+					if !ok {
+						maxWaitTime = starterWrapper.setting().stopMaxWaitTime
+					}
+					report(index, s.stop(ctx, starterWrapper, maxWaitTime))
+				}(i, begin, sorted[i], async, acquired)
+			}
+			tier.Wait()
+			begin = end
+		}
+	}()
+	allStopDone := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(allStopDone)
+	}()
+	return allStopDone
+}
+
+// StoppedStarters 未启动的模块名 该方法不会被正在进行的启动/停止阻塞
+func (s *StarterLoader) StoppedStarters() []string {
+	starters := s.snapshot()
+	if starters.isEmpty() {
+		return nil
+	}
+	return starters.stoppedStarters()
+}
+
+// IsAllStarted 是否所有启用的模块均已启动 没有任何模块时返回false 该方法不会被正在进行的启动/停止阻塞
+func (s *StarterLoader) IsAllStarted() bool {
+	return s.snapshot().allStarted()
+}
+
+// IsAnyStarted 是否存在已启动的模块 该方法不会被正在进行的启动/停止阻塞
+func (s *StarterLoader) IsAnyStarted() bool {
+	return s.snapshot().anyStarted()
+}
+
+// DisabledStarters 被设置为不启用的模块名 该方法不会被正在进行的启动/停止阻塞
+func (s *StarterLoader) DisabledStarters() []string {
+	starters := s.snapshot()
+	if starters.isEmpty() {
+		return nil
+	}
+	starterNames := make([]string, 0)
+	for _, wrapper := range *starters {
+		if wrapper.disabled() {
+			starterNames = append(starterNames, wrapper.getStarterName())
+		}
+	}
+	return starterNames
+}
+
+// 获取当前模块列表 无需持有生命周期操作锁
+func (s *StarterLoader) snapshot() *starterWrappers {
+	defer s.startersMutex.RUnlock()
+	s.startersMutex.RLock()
+	return s.starters
+}
+
+// GetStatus 获取指定模块的状态 该方法不会被正在进行的启动/停止阻塞
+func (s *StarterLoader) GetStatus(starterName string) (StarterStatus, error) {
+	starters := s.snapshot()
+	if starters.isEmpty() {
+		return StarterStatusNotStarted, errors.New("no starter")
+	}
+	wrapper := starters.find(starterName)
+	if wrapper == nil {
+		return StarterStatusNotStarted, errors.New("unknown starterName: " + starterName)
+	}
+	return wrapper.getStatus(), nil
+}
+
+// GetInstance 获取指定模块最近一次成功启动时返回的实例 模块重新启动时将清除上一次的实例
+func (s *StarterLoader) GetInstance(starterName string) (interface{}, error) {
+	defer s.Mutex.Unlock()
+	s.Mutex.Lock()
+	if s.starters.isEmpty() {
+		return nil, errors.New("no starter")
+	}
+	wrapper := s.starters.find(starterName)
+	if wrapper == nil {
+		return nil, errors.New("unknown starterName: " + starterName)
+	}
+	if wrapper.getStatus() == StarterStatusNotStarted {
+		return nil, errors.New("starter " + starterName + " never started")
+	}
+	return wrapper.instance, nil
+}
+
+// GetTypedInstance 获取指定模块最近一次成功启动时返回的实例 并转换为指定类型
+func GetTypedInstance[T any](loader *StarterLoader, starterName string) (T, error) {
+	var typed T
+	instance, err := loader.GetInstance(starterName)
+	if err != nil {
+		return typed, err
+	}
+	expected := reflect.TypeOf((*T)(nil)).Elem()
+	if instance == nil {
+		return typed, fmt.Errorf("starter %s has no instance, expected %s", starterName, expected)
+	}
+	typed, ok := instance.(T)
+	if !ok {
+		return typed, fmt.Errorf("starter %s instance type %T is not %s", starterName, instance, expected)
+	}
+	return typed, nil
+}
+
+// StartedStarters 已启动的模块名 该方法不会被正在进行的启动/停止阻塞
+func (s *StarterLoader) StartedStarters() []string {
+	starters := s.snapshot()
+	if starters.isEmpty() {
+		return nil
+	}
+	return starters.startedStarters()
+}
+
+// Stop 按starter加载顺序停止所有模块 忽略卸载配置
+func (s *StarterLoader) Stop(maxWaitTime time.Duration) ([]*StopResult, error) {
+	defer s.Mutex.Unlock()
+	s.Mutex.Lock()
+	s.beginShutdown()
+	if s.starters.isEmpty() {
+		return nil, errors.New("no starter")
+	}
+	stopResult := make([]*StopResult, 0)
+	for _, wrapper := range *s.starters {
+		stopResult = append(stopResult, s.stop(context.Background(), wrapper, maxWaitTime))
+	}
+	return stopResult, nil
+}
+
+// StopReverse 按启动的相反顺序依次停止所有已启动的模块 适用于未设置stopPriority的场景
+func (s *StarterLoader) StopReverse(maxWaitTime time.Duration) ([]*StopResult, error) {
+	defer s.Mutex.Unlock()
+	s.Mutex.Lock()
+	s.beginShutdown()
+	if s.starters.isEmpty() {
+		return nil, errors.New("no starter")
+	}
+	started := coll.SliceFilter(*s.starters, func(item *starterWrapper) bool {
+		return item.getStatus() == StarterStatusStarted
+	})
+	sort.Slice(started, func(i, j int) bool {
+		return started[i].startSeq > started[j].startSeq
+	})
+	stopResult := make([]*StopResult, len(started))
+	for i, wrapper := range started {
+		stopResult[i] = s.stop(context.Background(), wrapper, maxWaitTime)
+	}
+	return stopResult, nil
+}
+
+// StopStarter 停止指定的模块
+func (s *StarterLoader) StopStarter(starterName string, maxWaitTime time.Duration) (*StopResult, error) {
+	defer s.Mutex.Unlock()
+	s.Mutex.Lock()
+	if s.starters.isEmpty() {
+		return nil, errors.New("no starter")
+	}
+	wrapper := s.starters.find(starterName)
+	if wrapper == nil {
+		return nil, errors.New("unknown starterName: " + starterName)
+	}
+	return s.stop(context.Background(), wrapper, maxWaitTime), nil
+}
+
+// StopStarters 按传入顺序停止多个指定的模块 任一模块名不存在时不停止任何模块
+func (s *StarterLoader) StopStarters(maxWaitTime time.Duration, starterNames ...string) ([]*StopResult, error) {
+	defer s.Mutex.Unlock()
+	s.Mutex.Lock()
+	if s.starters.isEmpty() {
+		return nil, errors.New("no starter")
+	}
+	wrappers := make([]*starterWrapper, len(starterNames))
+	for i, starterName := range starterNames {
+		wrappers[i] = s.starters.find(starterName)
+		if wrappers[i] == nil {
+			return nil, errors.New("unknown starterName: " + starterName)
+		}
+	}
+	stopResult := make([]*StopResult, len(wrappers))
+	for i, wrapper := range wrappers {
+		stopResult[i] = s.stop(context.Background(), wrapper, maxWaitTime)
+	}
+	return stopResult, nil
+}
+
+// RestartStarter 重启指定的模块 如果模块已启动则先停止再启动
+// 返回停止阶段的结果(模块未启动时为nil)以及启动异常 模块未能成功停止时不再执行启动
+func (s *StarterLoader) RestartStarter(starterName string, maxWaitTime time.Duration) (*StopResult, error) {
+	defer s.Mutex.Unlock()
+	s.Mutex.Lock()
+	return s.restartStarter(starterName, maxWaitTime)
+}
+
+// 重启指定的模块 调用方需持有锁
+func (s *StarterLoader) restartStarter(starterName string, maxWaitTime time.Duration) (*StopResult, error) {
+	if s.starters.isEmpty() {
+		return nil, errors.New("no starter")
+	}
+	wrapper := s.starters.find(starterName)
+	if wrapper == nil {
+		return nil, errors.New("unknown starterName: " + starterName)
+	}
+	var stopResult *StopResult
+	if wrapper.getStatus() == StarterStatusStarted {
+		stopResult = s.stop(context.Background(), wrapper, maxWaitTime)
+		if !stopResult.Stopped {
+			return stopResult, errors.New("restart " + starterName + " failed: module not stopped")
+		}
+	}
+	return stopResult, s.start(wrapper)
+}
+
+// 启动指定的模块 如果已启动或正在启动则忽略
+func (s *StarterLoader) start(wrapper *starterWrapper) error {
+	if wrapper.startable() && !s.skipDisabled(wrapper) {
+		previous := s.markStarting(wrapper)
+		result := s.launch(wrapper)
+		wrapper.finishStart(result, previous)
+		return result.Error
+	}
+	return nil
+}
+
+// 模块被设置为不启用时输出日志并返回true
+func (s *StarterLoader) skipDisabled(wrapper *starterWrapper) bool {
+	if !wrapper.disabled() {
+		return false
+	}
+	s.log().Infoln(wrapper.getStarterName(), "is disabled, skip start")
+	return true
+}
+
+// 执行模块的启动及初始化方法 不修改模块状态
+func (s *StarterLoader) launch(wrapper *starterWrapper) (result *StartResult) {
+	starter := wrapper.starter
+	setting := starter.Setting()
+	starterName := wrapper.getStarterName()
+	_, span := s.startSpan(context.Background(), starterName, "starter.start/"+starterName)
+	defer func() {
+		if result.Error != nil {
+			span.RecordError(result.Error)
+		}
+		span.End()
+		s.recordStart(starterName, result.Duration, result.Error)
+		s.afterStart(result)
+	}()
+	s.beforeStart(starterName)
+	current := time.Now()
+	s.log().Traceln(starterName, "starting now...")
+	s.emit(starterName, LifecyclePhaseStarting, 0, nil)
+	instance, err := invokeStart(starterName, starter)
+	if err != nil && setting != nil {
+		for attempt := 1; attempt <= setting.maxStartRetries && err != nil; attempt++ {
+			s.log().WithError(err).Traceln(starterName, "start failed, retry attempt", attempt, "after", setting.startRetryBackoff)
+			time.Sleep(setting.startRetryBackoff)
+			instance, err = invokeStart(starterName, starter)
+		}
+	}
+	if err != nil {
+		s.log().WithError(err).Errorln(starterName, "start failed with error:", err)
+		duration := time.Since(current)
+		s.emit(starterName, LifecyclePhaseFailed, duration, err)
+		return &StartResult{StarterName: starterName, Index: wrapper.index, Duration: duration, Error: err}
+	}
+	if err = s.awaitInit(starterName, setting, instance); err != nil {
+		s.log().WithError(err).Errorln(starterName, "init failed with error:", err)
+		// 模块已启动但初始化失败 尽力停止该模块避免其脱离loader的管理继续运行
+		maxWaitTime := setting.stopMaxWaitTime
+		if maxWaitTime <= 0 {
+			maxWaitTime = s.defaultStopWait
+		}
+		if _, stopped, stopErr := invokeStop(context.Background(), starter, maxWaitTime, s.progressReporter(starterName)); stopErr != nil || !stopped {
+			s.log().WithError(stopErr).Warnln(starterName, "stop after init failure failed, module may still be running")
+		}
+		duration := time.Since(current)
+		s.emit(starterName, LifecyclePhaseFailed, duration, err)
+		return &StartResult{StarterName: starterName, Index: wrapper.index, Duration: duration, Error: err}
+	}
+	duration := time.Since(current)
+	s.log().Traceln(starterName, "started successful cost:", duration)
+	s.emit(starterName, LifecyclePhaseStarted, duration, nil)
+	return &StartResult{StarterName: starterName, Index: wrapper.index, Duration: duration, instance: instance}
+}
+
+// 在设置的等待时间内执行模块的初始化方法 未设置等待时间时直接执行
+func (s *StarterLoader) awaitInit(starterName string, setting *Setting, instance interface{}) error {
+	if setting == nil || setting.initMaxWaitTime <= 0 {
+		return invokeInit(starterName, setting, instance)
+	}
+	// 超时后初始化方法完成时可直接写入缓冲 避免goroutine无法退出
+	done := make(chan error, 1)
+	go func() {
+		done <- invokeInit(starterName, setting, instance)
+	}()
+	timer := time.NewTimer(setting.initMaxWaitTime)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		err := fmt.Errorf("%s init exceeding the maximum wait time %v", starterName, setting.initMaxWaitTime)
+		if setting.initTimeoutWarnOnly {
+			s.log().WithError(err).Warnln(starterName, "init is still running in background")
+			return nil
+		}
+		return err
+	}
+}
+
+// 执行模块设置的初始化方法 并将异常及panic转换为初始化异常
+func invokeInit(starterName string, setting *Setting, instance interface{}) (err error) {
+	if setting == nil {
+		return nil
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%s init panic: %v\n%s", starterName, r, debug.Stack())
+		}
+	}()
+	if setting.initHandler != nil {
+		setting.initHandler(instance)
+	}
+	if setting.initErrorHandler != nil {
+		if err = setting.initErrorHandler(instance); err != nil {
+			return fmt.Errorf("%s init failed: %w", starterName, err)
+		}
+	}
+	return nil
+}
+
+// 执行模块的Start方法 并将panic转换为启动异常
+func invokeStart(starterName string, starter Starter) (instance interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%s start panic: %v\n%s", starterName, r, debug.Stack())
+		}
+	}()
+	return starter.Start()
+}
+
+// 停止指定的模块 如果模块实现了ContextStarter则传入基于ctx及maxWaitTime派生的context
+// maxWaitTime未设置时使用加载器的默认停止等待时间
+func (s *StarterLoader) stop(ctx context.Context, wrapper *starterWrapper, maxWaitTime time.Duration) *StopResult {
+	starterName := wrapper.getStarterName()
+	if wrapper.getStatus() != StarterStatusStarted {
+		return &StopResult{StarterName: starterName, Index: wrapper.index, Skipped: true}
+	}
+	if maxWaitTime <= 0 {
+		maxWaitTime = s.defaultStopWait
+	}
+	starter := wrapper.starter
+	ctx, span := s.startSpan(ctx, starterName, "starter.stop/"+starterName)
+	defer span.End()
+	wrapper.setStatus(StarterStatusStopping)
+	s.beforeStop(starterName)
+	current := time.Now()
+	s.log().Traceln(starterName, "stopping now...")
+	s.emit(starterName, LifecyclePhaseStopping, 0, nil)
+	gracefully, stopped, attempts, err := s.escalateStop(ctx, starterName, starter, maxWaitTime)
+	// 未能优雅停机且耗时达到等待时间 视为超时
+	timedOut := !gracefully && maxWaitTime > 0 && time.Since(current) >= maxWaitTime
+	forced := false
+	if forcible, ok := starter.(Forcible); ok && !gracefully {
+		// 未能优雅停机 升级为强制停止
+		s.log().WithError(err).Warnln(starterName, "not stopped gracefully, force stop now")
+		forced = true
+		if forceErr := invokeForceStop(forcible); forceErr != nil {
+			err = errors.Join(err, forceErr)
+		} else {
+			stopped = true
+		}
+	}
+	// 附加模块名称 原始异常可通过errors.Unwrap、errors.Is/As获取
+	switch {
+	case timedOut && err != nil:
+		err = &stopTimeoutError{starterName: starterName, err: err}
+	case timedOut:
+		err = fmt.Errorf("%s: %w", starterName, ErrStopTimeout)
+	case err != nil:
+		err = fmt.Errorf("%s: %w", starterName, err)
+	}
+	duration := time.Since(current)
+	if err != nil {
+		s.log().WithError(err).Errorln(starterName, "stop failed with error", err)
+	} else {
+		s.log().Traceln(starterName, "stopped successful cost:", duration)
+	}
+	if err == nil && stopped {
+		s.emit(starterName, LifecyclePhaseStopped, duration, nil)
+	} else {
+		s.emit(starterName, LifecyclePhaseFailed, duration, err)
+	}
+	wrapper.setLastError(err)
+	if stopped {
+		wrapper.setStatus(StarterStatusStopped)
+	} else {
+		wrapper.setStatus(StarterStatusStarted)
+	}
+	result := &StopResult{
+		StarterName: starterName,
+		Index:       wrapper.index,
+		Error:       err,
+		Gracefully:  gracefully,
+		Stopped:     stopped,
+		Duration:    duration,
+		Forced:      forced,
+		Attempts:    attempts,
+		TimedOut:    timedOut,
+	}
+	if err != nil {
+		span.RecordError(err)
+	}
+	s.recordStop(starterName, duration, result)
+	s.afterStop(result)
+	return result
+}
+
+// 执行模块的停止方法 开启分阶段停止时先以较短的等待时间尝试，未能优雅停机时再以完整的等待时间重试
+// 未开启分阶段停止时attempts为nil
+func (s *StarterLoader) escalateStop(ctx context.Context, starterName string, starter Starter, maxWaitTime time.Duration) (gracefully, stopped bool, attempts []StopAttempt, err error) {
+	if s.softStopFraction <= 0 || s.softStopFraction >= 1 {
+		gracefully, stopped, err = invokeStop(ctx, starter, maxWaitTime, s.progressReporter(starterName))
+		return gracefully, stopped, nil, err
+	}
+	for _, waitTime := range []time.Duration{time.Duration(float64(maxWaitTime) * s.softStopFraction), maxWaitTime} {
+		current := time.Now()
+		gracefully, stopped, err = invokeStop(ctx, starter, waitTime, s.progressReporter(starterName))
+		attempts = append(attempts, StopAttempt{
+			MaxWaitTime: waitTime,
+			Error:       err,
+			Stopped:     stopped,
+			Gracefully:  gracefully,
+			Duration:    time.Since(current),
+		})
+		if (gracefully && stopped) || ctx.Err() != nil {
+			break
+		}
+		s.log().WithError(err).Warnln(starterName, "not stopped gracefully within", waitTime, "retry with", maxWaitTime)
+	}
+	return gracefully, stopped, attempts, err
+}
+
+// 执行模块的强制停止方法 并将异常及panic转换为强制停止异常
+func invokeForceStop(forcible Forcible) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("force stop panic: %v\n%s", r, debug.Stack())
+		}
+	}()
+	if err = forcible.ForceStop(); err != nil {
+		return fmt.Errorf("force stop failed: %w", err)
+	}
+	return nil
+}
+
+// 执行模块的停止方法 并将panic转换为停止异常 此时视为模块未停止
+func invokeStop(ctx context.Context, starter Starter, maxWaitTime time.Duration, report func(percent float64, remaining int)) (gracefully, stopped bool, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			gracefully, stopped = false, false
+			err = fmt.Errorf("stop panic: %v\n%s", r, debug.Stack())
+		}
+	}()
+	if contextStarter, ok := starter.(ContextStarter); ok {
+		var stopCtx context.Context
+		var cancel context.CancelFunc
+		if maxWaitTime > 0 {
+			stopCtx, cancel = context.WithTimeout(ctx, maxWaitTime)
+		} else {
+			// 未设置等待时间时仅受全局等待时间的限制
+			stopCtx, cancel = context.WithCancel(ctx)
+		}
+		defer cancel()
+		return contextStarter.StopWithContext(stopCtx)
+	}
+	if progressReporter, ok := starter.(ProgressReporter); ok {
+		return progressReporter.StopWithProgress(maxWaitTime, report)
 	}
+	return starter.Stop(maxWaitTime)
 }
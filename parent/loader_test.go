@@ -2,8 +2,15 @@ package parent
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/acexy/golang-toolkit/util/coll"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -73,6 +80,227 @@ func (g gin) Stop(maxWaitTime time.Duration) (gracefully bool, stopped bool, err
 	return false, false, errors.New("something error")
 }
 
+// recorder 记录启动/停止顺序的测试模块
+type recorder struct {
+	name   string
+	record *[]string
+}
+
+func (r recorder) Setting() *Setting {
+	return NewSetting(r.name, 0, false, time.Second, nil)
+}
+
+func (r recorder) Start() (interface{}, error) {
+	*r.record = append(*r.record, r.name)
+	return &r, nil
+}
+
+func (r recorder) Stop(maxWaitTime time.Duration) (gracefully bool, stopped bool, err error) {
+	*r.record = append(*r.record, r.name)
+	return true, true, nil
+}
+
+// sleeper 启动耗时可控的测试模块
+type sleeper struct {
+	name  string
+	delay time.Duration
+}
+
+func (s sleeper) Setting() *Setting {
+	return NewSetting(s.name, 0, false, time.Second, nil)
+}
+
+func (s sleeper) Start() (interface{}, error) {
+	time.Sleep(s.delay)
+	return &s, nil
+}
+
+func (s sleeper) Stop(maxWaitTime time.Duration) (gracefully bool, stopped bool, err error) {
+	return true, true, nil
+}
+
+// waiter 实现ContextStarter 停止时等待context结束
+type waiter struct {
+	canceled chan struct{}
+}
+
+func (w waiter) Setting() *Setting {
+	return NewSetting("waiter", 0, false, time.Second*5, nil)
+}
+
+func (w waiter) Start() (interface{}, error) {
+	return &w, nil
+}
+
+func (w waiter) Stop(maxWaitTime time.Duration) (gracefully bool, stopped bool, err error) {
+	return false, false, errors.New("should use StopWithContext")
+}
+
+func (w waiter) StopWithContext(ctx context.Context) (gracefully bool, stopped bool, err error) {
+	<-ctx.Done()
+	if w.canceled != nil {
+		close(w.canceled)
+	}
+	return false, true, ctx.Err()
+}
+
+// tiered 停止时记录完成时间的测试模块
+type tiered struct {
+	name     string
+	priority uint
+	delay    time.Duration
+	finished *sync.Map
+}
+
+func (t tiered) Setting() *Setting {
+	return NewSetting(t.name, t.priority, false, time.Second, nil)
+}
+
+func (t tiered) Start() (interface{}, error) {
+	return &t, nil
+}
+
+func (t tiered) Stop(maxWaitTime time.Duration) (gracefully bool, stopped bool, err error) {
+	time.Sleep(t.delay)
+	t.finished.Store(t.name, time.Now())
+	return true, true, nil
+}
+
+// panicker 启动或停止时panic的测试模块
+type panicker struct {
+	onStart bool
+}
+
+func (p panicker) Setting() *Setting {
+	return NewSetting("panicker", 1, true, time.Second, nil)
+}
+
+func (p panicker) Start() (interface{}, error) {
+	if p.onStart {
+		panic("start boom")
+	}
+	return &p, nil
+}
+
+func (p panicker) Stop(maxWaitTime time.Duration) (gracefully bool, stopped bool, err error) {
+	panic("stop boom")
+}
+
+// prioritized 设置启动优先级的测试模块
+type prioritized struct {
+	recorder
+	priority  uint
+	dependsOn []string
+}
+
+func (p prioritized) Setting() *Setting {
+	return NewSetting(p.name, 0, false, time.Second, nil).WithStartPriority(p.priority).WithDependsOn(p.dependsOn...)
+}
+
+// toggled 可通过enabled控制是否启用的测试模块
+type toggled struct {
+	recorder
+	enabled bool
+}
+
+func (t toggled) Setting() *Setting {
+	return NewSetting(t.name, 0, false, time.Second, nil).WithEnabled(func() bool {
+		return t.enabled
+	})
+}
+
+// initFailing 初始化失败的测试模块
+type initFailing struct {
+	recorder
+}
+
+func (i initFailing) Setting() *Setting {
+	return NewSetting(i.name, 0, false, time.Second, nil).WithInitErrorHandler(func(instance interface{}) error {
+		return errors.New("wiring failed")
+	})
+}
+
+// initPanicker 初始化时panic的测试模块
+type initPanicker struct {
+	recorder
+}
+
+func (i initPanicker) Setting() *Setting {
+	return NewSetting(i.name, 0, false, time.Second, func(instance interface{}) {
+		panic("init boom")
+	})
+}
+
+// slowInit 初始化耗时超过等待时间的测试模块
+type slowInit struct {
+	recorder
+	warnOnly bool
+}
+
+func (i slowInit) Setting() *Setting {
+	return NewSetting(i.name, 0, false, time.Second, func(instance interface{}) {
+		time.Sleep(time.Millisecond * 300)
+	}).WithInitMaxWaitTime(time.Millisecond*50, i.warnOnly)
+}
+
+// flaky 前几次启动失败的测试模块
+type flaky struct {
+	failures int
+	attempts int
+}
+
+func (f *flaky) Setting() *Setting {
+	return NewSetting("flaky", 0, false, time.Second, nil).WithStartRetry(3, time.Millisecond*10)
+}
+
+func (f *flaky) Start() (interface{}, error) {
+	f.attempts++
+	if f.attempts <= f.failures {
+		return nil, errors.New("not reachable")
+	}
+	return f, nil
+}
+
+func (f *flaky) Stop(maxWaitTime time.Duration) (gracefully bool, stopped bool, err error) {
+	return true, true, nil
+}
+
+// failing 启动失败的测试模块
+type failing struct {
+	name string
+}
+
+func (f failing) Setting() *Setting {
+	return NewSetting(f.name, 0, false, time.Second, nil)
+}
+
+func (f failing) Start() (interface{}, error) {
+	return nil, errors.New(f.name + " start failed")
+}
+
+func (f failing) Stop(maxWaitTime time.Duration) (gracefully bool, stopped bool, err error) {
+	return true, true, nil
+}
+
+// lingering 允许异步停止且停止耗时可控的测试模块
+type lingering struct {
+	name  string
+	delay time.Duration
+}
+
+func (l lingering) Setting() *Setting {
+	return NewSetting(l.name, 0, true, time.Second*5, nil)
+}
+
+func (l lingering) Start() (interface{}, error) {
+	return &l, nil
+}
+
+func (l lingering) Stop(maxWaitTime time.Duration) (gracefully bool, stopped bool, err error) {
+	time.Sleep(l.delay)
+	return true, true, nil
+}
+
 var starters []Starter
 
 func init() {
@@ -157,3 +385,1302 @@ func TestStarterControl(t *testing.T) {
 	_ = loader.Start()
 	fmt.Println(loader.StoppedStarters())
 }
+
+func TestNewStarterLoaderIndependent(t *testing.T) {
+	first := NewStarterLoader([]Starter{&gorm{}})
+	second := NewStarterLoader([]Starter{&gin{}})
+	if first == second {
+		t.Fatal("NewStarterLoader should return independent loaders")
+	}
+	if (*second.starters)[0].getStarterName() != "gin" {
+		t.Fatal("second loader lost its starters")
+	}
+	if SharedStarterLoader([]Starter{&gorm{}}) != SharedStarterLoader([]Starter{&gin{}}) {
+		t.Fatal("SharedStarterLoader should always return the same loader")
+	}
+}
+
+func TestAddStarterToEmptyLoader(t *testing.T) {
+	var record []string
+	loader := NewStarterLoader(nil)
+	loader.AddStarter(&recorder{name: "first", record: &record})
+	loader.AddStarter(&recorder{name: "second", record: &record})
+	if err := loader.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(record) != "[first second]" {
+		t.Fatal("unexpected start order", record)
+	}
+}
+
+func TestStartEmptyLoader(t *testing.T) {
+	loader := NewStarterLoader(nil)
+	if err := loader.Start(); err == nil || err.Error() != "miss starters" {
+		t.Fatal("expected miss starters error, got", err)
+	}
+	if _, err := loader.StartDetailed(); err == nil {
+		t.Fatal("expected miss starters error from StartDetailed")
+	}
+}
+
+func TestStartWithContext(t *testing.T) {
+	loader := NewStarterLoader([]Starter{
+		&sleeper{name: "fast"},
+		&sleeper{name: "slow", delay: time.Millisecond * 300},
+		&sleeper{name: "never"},
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+	err := loader.StartWithContext(ctx)
+	var aborted *StartAbortedError
+	if !errors.As(err, &aborted) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatal("expected deadline abort, got", err)
+	}
+	if aborted.StarterName != "slow" || fmt.Sprint(aborted.Started) != "[fast]" {
+		t.Fatalf("unexpected abort detail %+v", aborted)
+	}
+	fmt.Println(err)
+}
+
+func TestStartWithTimeout(t *testing.T) {
+	loader := NewStarterLoader([]Starter{
+		&sleeper{name: "fast"},
+		&sleeper{name: "hanging", delay: time.Millisecond * 300},
+		&sleeper{name: "never"},
+	})
+	current := time.Now()
+	err := loader.StartWithTimeout(time.Millisecond * 50)
+	if time.Since(current) > time.Millisecond*250 {
+		t.Fatal("start should return once the timeout is reached")
+	}
+	var aborted *StartAbortedError
+	if !errors.As(err, &aborted) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatal("expected timeout abort, got", err)
+	}
+	if !strings.Contains(err.Error(), "hanging") || !strings.Contains(err.Error(), "[fast]") {
+		t.Fatal("timeout error should name the hanging and started modules", err)
+	}
+	if status, _ := loader.GetStatus("never"); status != StarterStatusNotStarted {
+		t.Fatal("modules after the timeout should not start", status)
+	}
+}
+
+func TestStopWithContextStarter(t *testing.T) {
+	loader := NewStarterLoader([]Starter{&waiter{}})
+	if err := loader.Start(); err != nil {
+		t.Fatal(err)
+	}
+	current := time.Now()
+	result, err := loader.Stop(time.Millisecond * 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !errors.Is(result[0].Error, context.DeadlineExceeded) || !result[0].Stopped {
+		t.Fatalf("unexpected stop result %+v", result[0])
+	}
+	if time.Since(current) > time.Second {
+		t.Fatal("context deadline not propagated")
+	}
+}
+
+// contextProbe 实现ContextStarter 记录停止时收到的context
+type contextProbe struct {
+	err      error
+	deadline time.Time
+}
+
+func (c *contextProbe) Setting() *Setting {
+	return NewSetting("probe", 0, false, 0, nil)
+}
+
+func (c *contextProbe) Start() (interface{}, error) {
+	return c, nil
+}
+
+func (c *contextProbe) Stop(maxWaitTime time.Duration) (gracefully bool, stopped bool, err error) {
+	return false, false, errors.New("should use StopWithContext")
+}
+
+func (c *contextProbe) StopWithContext(ctx context.Context) (gracefully bool, stopped bool, err error) {
+	c.err = ctx.Err()
+	c.deadline, _ = ctx.Deadline()
+	return true, true, nil
+}
+
+func TestStopWithContextNoWaitTime(t *testing.T) {
+	module := &contextProbe{}
+	loader := NewStarterLoader([]Starter{module})
+	if err := loader.Start(); err != nil {
+		t.Fatal(err)
+	}
+	result, err := loader.StopBySetting(time.Second * 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if module.err != nil || !result[0].Gracefully {
+		t.Fatal("context should be live when the module has no wait time", module.err)
+	}
+	if remaining := time.Until(module.deadline); remaining < time.Second*4 {
+		t.Fatal("context should carry the global deadline", remaining)
+	}
+}
+
+func TestStopBySettingOrder(t *testing.T) {
+	loader := NewStarterLoader(starters)
+	if err := loader.Start(); err != nil {
+		t.Fatal(err)
+	}
+	result, err := loader.StopBySetting()
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := coll.SliceCollect(result, func(item *StopResult) string {
+		return item.StarterName
+	})
+	if fmt.Sprint(names) != "[gin unnamed-1 gorm]" {
+		t.Fatal("unexpected stop result order", names)
+	}
+}
+
+func TestStopBySettingStream(t *testing.T) {
+	loader := NewStarterLoader(starters)
+	if err := loader.Start(); err != nil {
+		t.Fatal(err)
+	}
+	stream, err := loader.StopBySettingStream()
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, 0)
+	for result := range stream {
+		names = append(names, result.StarterName)
+	}
+	sort.Strings(names)
+	if fmt.Sprint(names) != "[gin gorm unnamed-1]" {
+		t.Fatal("every module should be reported once", names)
+	}
+	if len(loader.StartedStarters()) != 1 {
+		t.Fatal("only the failing module should keep running", loader.StartedStarters())
+	}
+}
+
+func TestStopBySettingStreamTimeout(t *testing.T) {
+	canceled := make(chan struct{})
+	loader := NewStarterLoader([]Starter{&waiter{canceled: canceled}, &tiered{name: "db", priority: 10, finished: &sync.Map{}}})
+	if err := loader.Start(); err != nil {
+		t.Fatal(err)
+	}
+	stream, err := loader.StopBySettingStream(time.Millisecond * 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := make(map[string]*StopResult)
+	for result := range stream {
+		results[result.StarterName] = result
+	}
+	if len(results) != 2 || !results["db"].TimedOut {
+		t.Fatal("unfinished module should be reported as timed out", results)
+	}
+}
+
+func TestStopBySettingTiers(t *testing.T) {
+	var finished sync.Map
+	loader := NewStarterLoader([]Starter{
+		&tiered{name: "db", priority: 20, finished: &finished},
+		&tiered{name: "web", priority: 0, delay: time.Millisecond * 60, finished: &finished},
+		&tiered{name: "web2", priority: 0, delay: time.Millisecond * 60, finished: &finished},
+		&tiered{name: "cache", priority: 10, delay: time.Millisecond * 30, finished: &finished},
+	})
+	if err := loader.Start(); err != nil {
+		t.Fatal(err)
+	}
+	current := time.Now()
+	if _, err := loader.StopBySetting(); err != nil {
+		t.Fatal(err)
+	}
+	if time.Since(current) > time.Millisecond*150 {
+		t.Fatal("modules in the same tier should stop concurrently")
+	}
+	at := func(name string) time.Time {
+		v, _ := finished.Load(name)
+		return v.(time.Time)
+	}
+	if !at("web").Before(at("cache")) || !at("web2").Before(at("cache")) || !at("cache").Before(at("db")) {
+		t.Fatal("tiers finished out of order")
+	}
+}
+
+func TestStartedStarters(t *testing.T) {
+	loader := NewStarterLoader([]Starter{&sleeper{name: "first"}, &sleeper{}, &sleeper{name: "third"}})
+	if len(loader.StartedStarters()) != 0 {
+		t.Fatal("nothing should be started yet")
+	}
+	if err := loader.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loader.StopStarter("third", time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(loader.StartedStarters()) != "[first unnamed-1]" {
+		t.Fatal("unexpected started starters", loader.StartedStarters())
+	}
+}
+
+func TestGetStatus(t *testing.T) {
+	loader := NewStarterLoader([]Starter{&sleeper{name: "first"}})
+	status, err := loader.GetStatus("first")
+	if err != nil || status != StarterStatusNotStarted {
+		t.Fatal("unexpected status", status, err)
+	}
+	_ = loader.Start()
+	status, _ = loader.GetStatus("first")
+	if status.String() != "started" {
+		t.Fatal("unexpected status", status)
+	}
+	_, _ = loader.StopStarter("first", time.Second)
+	status, _ = loader.GetStatus("first")
+	fmt.Println(status)
+	if status != StarterStatusStopped {
+		t.Fatal("unexpected status", status)
+	}
+	if _, err = loader.GetStatus("unknown"); err == nil {
+		t.Fatal("unknown starter should return error")
+	}
+}
+
+func TestStartDetailed(t *testing.T) {
+	loader := NewStarterLoader([]Starter{&sleeper{name: "slow", delay: time.Millisecond * 50}, &sleeper{name: "fast"}})
+	result, err := loader.StartDetailed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 2 || result[0].StarterName != "slow" || result[0].Duration < time.Millisecond*50 {
+		t.Fatalf("unexpected start result %+v", result[0])
+	}
+	stopResult, _ := loader.Stop(time.Second)
+	showStopResult(stopResult)
+}
+
+func TestStartPanic(t *testing.T) {
+	loader := NewStarterLoader([]Starter{&sleeper{name: "first"}, &panicker{onStart: true}})
+	err := loader.Start()
+	if err == nil || !strings.Contains(err.Error(), "panicker") {
+		t.Fatal("expected panic error naming the starter, got", err)
+	}
+	if fmt.Sprint(loader.StartedStarters()) != "[first]" {
+		t.Fatal("unexpected started starters", loader.StartedStarters())
+	}
+}
+
+func TestStopPanic(t *testing.T) {
+	loader := NewStarterLoader([]Starter{&sleeper{name: "first"}, &panicker{}, &sleeper{name: "third"}})
+	if err := loader.Start(); err != nil {
+		t.Fatal(err)
+	}
+	result, err := loader.StopBySetting()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 3 {
+		t.Fatal("missing stop results", len(result))
+	}
+	for _, v := range result {
+		if v.StarterName == "panicker" && (v.Error == nil || v.Stopped) {
+			t.Fatalf("panic should be reported %+v", v)
+		}
+		if v.StarterName == "panicker" && !strings.HasPrefix(v.Error.Error(), "panicker: stop panic: stop boom") {
+			t.Fatal("panic error should be prefixed with the module name once", v.Error)
+		}
+		if v.StarterName != "panicker" && !v.Stopped {
+			t.Fatalf("other starters should stop %+v", v)
+		}
+	}
+}
+
+func TestStartWithRollback(t *testing.T) {
+	var record []string
+	loader := NewStarterLoader([]Starter{
+		&recorder{name: "first", record: &record},
+		&recorder{name: "second", record: &record},
+		&panicker{onStart: true},
+	})
+	err := loader.StartWithRollback()
+	if err == nil {
+		t.Fatal("expected start error")
+	}
+	fmt.Println(err)
+	if fmt.Sprint(record) != "[first second second first]" {
+		t.Fatal("unexpected rollback order", record)
+	}
+	if len(loader.StartedStarters()) != 0 {
+		t.Fatal("all started modules should be rolled back", loader.StartedStarters())
+	}
+}
+
+func TestStartBySetting(t *testing.T) {
+	var record []string
+	loader := NewStarterLoader([]Starter{
+		&recorder{name: "gin", record: &record},
+		&prioritized{recorder: recorder{name: "gorm", record: &record}, priority: 2},
+		&recorder{name: "grpc", record: &record},
+		&prioritized{recorder: recorder{name: "redis", record: &record}, priority: 1},
+	})
+	if err := loader.StartBySetting(); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(record) != "[redis gorm gin grpc]" {
+		t.Fatal("unexpected start order", record)
+	}
+}
+
+func TestStartBySettingWithDependency(t *testing.T) {
+	var record []string
+	loader := NewStarterLoader([]Starter{
+		&prioritized{recorder: recorder{name: "a", record: &record}, priority: 5},
+		&prioritized{recorder: recorder{name: "b", record: &record}, priority: 1, dependsOn: []string{"c"}},
+		&prioritized{recorder: recorder{name: "c", record: &record}, priority: 9},
+	})
+	planned, _ := loader.PlanStartBySetting()
+	if err := loader.StartBySetting(); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(record) != "[c b a]" || fmt.Sprint(planned) != fmt.Sprint(record) {
+		t.Fatal("dependencies should start just before their dependents in priority order", planned, record)
+	}
+}
+
+func TestStopBySettingDeadlineCancel(t *testing.T) {
+	canceled := make(chan struct{})
+	loader := NewStarterLoader([]Starter{&waiter{canceled: canceled}, &tiered{name: "db", priority: 10, finished: &sync.Map{}}})
+	if err := loader.Start(); err != nil {
+		t.Fatal(err)
+	}
+	result, err := loader.StopBySetting(time.Millisecond * 100)
+	if err == nil {
+		t.Fatal("expected global timeout")
+	}
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("in-flight stop should be canceled by the global deadline")
+	}
+	if len(result) != 2 || !result[1].TimedOut || result[1].StarterName != "db" {
+		t.Fatalf("unfinished module should be marked timed out %+v", result[1])
+	}
+	status, _ := loader.GetStatus("db")
+	if status != StarterStatusStarted {
+		t.Fatal("module not stopped should keep started status", status)
+	}
+}
+
+func TestStartRetry(t *testing.T) {
+	module := &flaky{failures: 2}
+	loader := NewStarterLoader([]Starter{module})
+	if err := loader.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if module.attempts != 3 {
+		t.Fatal("unexpected attempts", module.attempts)
+	}
+	module = &flaky{failures: 5}
+	loader = NewStarterLoader([]Starter{module})
+	if err := loader.Start(); err == nil || module.attempts != 4 {
+		t.Fatal("should give up after max retries", module.attempts, err)
+	}
+}
+
+func TestRestartStarter(t *testing.T) {
+	var record []string
+	loader := NewStarterLoader([]Starter{&recorder{name: "first", record: &record}})
+	result, err := loader.RestartStarter("first", time.Second)
+	if err != nil || result != nil {
+		t.Fatal("restart of a not started module should only start it", result, err)
+	}
+	result, err = loader.RestartStarter("first", time.Second)
+	if err != nil || !result.Stopped {
+		t.Fatal("unexpected restart result", result, err)
+	}
+	status, _ := loader.GetStatus("first")
+	if status != StarterStatusStarted || fmt.Sprint(record) != "[first first first]" {
+		t.Fatal("module should be started again", status, record)
+	}
+	if _, err = loader.RestartStarter("unknown", time.Second); err == nil {
+		t.Fatal("unknown starter should return error")
+	}
+}
+
+func TestStopStarters(t *testing.T) {
+	loader := NewStarterLoader([]Starter{&gorm{}, &gin{}, &sleeper{name: "other"}})
+	if err := loader.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loader.StopStarters(time.Second, "gorm", "unknown"); err == nil {
+		t.Fatal("unknown starter should return error")
+	}
+	if len(loader.StartedStarters()) != 3 {
+		t.Fatal("nothing should be stopped when a name is unknown")
+	}
+	result, err := loader.StopStarters(time.Second, "gorm", "gin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	showStopResult(result)
+	if len(result) != 2 || result[0].StarterName != "gorm" || result[1].StarterName != "gin" {
+		t.Fatal("unexpected stop results", result)
+	}
+}
+
+func TestGetInstance(t *testing.T) {
+	loader := NewStarterLoader([]Starter{&sleeper{name: "first"}})
+	if _, err := loader.GetInstance("first"); err == nil {
+		t.Fatal("never started module should return error")
+	}
+	_ = loader.Start()
+	instance, err := loader.GetInstance("first")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := instance.(*sleeper); !ok || v.name != "first" {
+		t.Fatal("unexpected instance", instance)
+	}
+}
+
+func TestGetTypedInstance(t *testing.T) {
+	loader := NewStarterLoader([]Starter{&gorm{}})
+	_ = loader.Start()
+	if _, err := GetTypedInstance[*gorm](loader, "gorm"); err != nil {
+		t.Fatal(err)
+	}
+	_, err := GetTypedInstance[*redis](loader, "gorm")
+	fmt.Println(err)
+	if err == nil || !strings.Contains(err.Error(), "*parent.gorm") || !strings.Contains(err.Error(), "*parent.redis") {
+		t.Fatal("type mismatch should name both types", err)
+	}
+}
+
+func TestRemoveStarter(t *testing.T) {
+	var record []string
+	loader := NewStarterLoader([]Starter{&recorder{name: "first", record: &record}, &recorder{name: "second", record: &record}})
+	_ = loader.Start()
+	if err := loader.RemoveStarter("second"); err == nil {
+		t.Fatal("started module should not be removed")
+	}
+	_, _ = loader.Stop(time.Second)
+	if err := loader.RemoveStarter("second"); err != nil {
+		t.Fatal(err)
+	}
+	if err := loader.RemoveStarter("second"); err == nil {
+		t.Fatal("removed module should be unknown")
+	}
+	record = nil
+	_ = loader.Start()
+	if fmt.Sprint(record) != "[first]" {
+		t.Fatal("removed module should not start", record)
+	}
+}
+
+func TestReplaceStarter(t *testing.T) {
+	var oldRecord, newRecord []string
+	loader := NewStarterLoader([]Starter{
+		&recorder{name: "first", record: &oldRecord},
+		&recorder{name: "second", record: &oldRecord},
+		&recorder{name: "third", record: &oldRecord},
+	})
+	_ = loader.Start()
+	if err := loader.ReplaceStarter("second", &recorder{name: "second", record: &newRecord}); err == nil {
+		t.Fatal("started module should not be replaced")
+	}
+	if err := loader.ReplaceStarter("second", &recorder{name: "other", record: &newRecord}, time.Second); err == nil {
+		t.Fatal("replacement with a different name should be rejected")
+	}
+	if _, err := loader.StopStarter("second", time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if err := loader.ReplaceStarter("second", &recorder{name: "second", record: &newRecord}); err != nil {
+		t.Fatal(err)
+	}
+	if status, _ := loader.GetStatus("second"); status != StarterStatusNotStarted {
+		t.Fatal("replaced module should not be started", status)
+	}
+	oldRecord = nil
+	if err := loader.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if len(oldRecord) != 0 || fmt.Sprint(newRecord) != "[second]" {
+		t.Fatal("only the replacement should start", oldRecord, newRecord)
+	}
+	if fmt.Sprint(loader.StartedStarters()) != "[first second third]" {
+		t.Fatal("replacement should keep its position", loader.StartedStarters())
+	}
+	if err := loader.ReplaceStarter("third", &recorder{name: "third", record: &newRecord}, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(oldRecord) != "[third]" {
+		t.Fatal("started module should be stopped before replace", oldRecord)
+	}
+}
+
+func TestStopNotStarted(t *testing.T) {
+	loader := NewStarterLoader([]Starter{&sleeper{name: "first"}})
+	result, err := loader.StopStarter("first", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Skipped || result.Error != nil || result.Stopped {
+		t.Fatalf("never started module should be skipped %+v", result)
+	}
+}
+
+func TestTransitionalStatus(t *testing.T) {
+	loader := NewStarterLoader([]Starter{&sleeper{name: "slow", delay: time.Millisecond * 200}})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*20)
+	defer cancel()
+	_ = loader.StartWithContext(ctx)
+	status, _ := loader.GetStatus("slow")
+	if status != StarterStatusStarting {
+		t.Fatal("slow module should be starting", status)
+	}
+	time.Sleep(time.Millisecond * 300)
+	status, _ = loader.GetStatus("slow")
+	if status != StarterStatusStarted {
+		t.Fatal("slow module should be started", status)
+	}
+}
+
+func TestStatusDuringSlowStart(t *testing.T) {
+	loader := NewStarterLoader([]Starter{
+		&sleeper{name: "first", delay: time.Millisecond * 200},
+		&sleeper{name: "second", delay: time.Millisecond * 200},
+		&sleeper{name: "third", delay: time.Millisecond * 200},
+	})
+	done := make(chan error)
+	go func() {
+		done <- loader.Start()
+	}()
+	time.Sleep(time.Millisecond * 50)
+	previous := 4
+	for i := 0; i < 3; i++ {
+		current := time.Now()
+		stopped := loader.StoppedStarters()
+		if time.Since(current) > time.Millisecond*50 {
+			t.Fatal("StoppedStarters blocked by Start")
+		}
+		if len(stopped) >= previous {
+			t.Fatal("not started set should shrink", stopped)
+		}
+		previous = len(stopped)
+		time.Sleep(time.Millisecond * 200)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStartContinueOnError(t *testing.T) {
+	loader := NewStarterLoader([]Starter{&redis{}, &failing{name: "gin"}, &gorm{}})
+	result := loader.StartContinueOnError()
+	if len(result) != 3 || result[1].StarterName != "gin" || result[1].Error == nil {
+		t.Fatal("gin failure should be reported", result)
+	}
+	if fmt.Sprint(loader.StartedStarters()) != "[unnamed-1 gorm]" {
+		t.Fatal("other modules should be started", loader.StartedStarters())
+	}
+}
+
+func TestStopResultJSON(t *testing.T) {
+	failed, _ := json.Marshal(&StopResult{StarterName: "gin", Index: 2, Error: errors.New("something error")})
+	if string(failed) != `{"starterName":"gin","index":2,"error":"something error","stopped":false,"gracefully":false,"skipped":false,"timedOut":false,"forced":false,"duration":"0s","attempts":null}` {
+		t.Fatal("unexpected json", string(failed))
+	}
+	succeeded, _ := json.Marshal([]*StopResult{{StarterName: "gorm", Stopped: true, Gracefully: true, Duration: time.Second}})
+	if string(succeeded) != `[{"starterName":"gorm","index":0,"error":null,"stopped":true,"gracefully":true,"skipped":false,"timedOut":false,"forced":false,"duration":"1s","attempts":null}]` {
+		t.Fatal("unexpected json", string(succeeded))
+	}
+	escalated, _ := json.Marshal(&StopResult{StarterName: "redis", Index: 1, Stopped: true, Gracefully: true, Duration: time.Second, Attempts: []StopAttempt{
+		{MaxWaitTime: time.Millisecond * 500, Error: errors.New("timeout"), Stopped: false, Duration: time.Millisecond * 500},
+		{MaxWaitTime: time.Second, Stopped: true, Gracefully: true, Duration: time.Millisecond * 500},
+	}})
+	if string(escalated) != `{"starterName":"redis","index":1,"error":null,"stopped":true,"gracefully":true,"skipped":false,"timedOut":false,"forced":false,"duration":"1s",`+
+		`"attempts":[{"maxWaitTime":"500ms","error":"timeout","stopped":false,"gracefully":false,"duration":"500ms"},{"maxWaitTime":"1s","error":null,"stopped":true,"gracefully":true,"duration":"500ms"}]}` {
+		t.Fatal("unexpected json", string(escalated))
+	}
+}
+
+func TestStartStopCycle(t *testing.T) {
+	var record []string
+	loader := NewStarterLoader([]Starter{&recorder{name: "first", record: &record}, &recorder{name: "second", record: &record}})
+	var previous interface{}
+	for cycle := 0; cycle < 2; cycle++ {
+		record = nil
+		if err := loader.Start(); err != nil {
+			t.Fatal(err)
+		}
+		if err := loader.Start(); err != nil {
+			t.Fatal("restarting started modules should be a no-op", err)
+		}
+		instance, _ := loader.GetInstance("first")
+		if instance == nil || instance == previous {
+			t.Fatal("start should replace the previous instance", cycle)
+		}
+		previous = instance
+		result, err := loader.Stop(time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, r := range result {
+			if !r.Stopped || r.Skipped || r.Error != nil {
+				t.Fatal("started module should be stopped", r)
+			}
+		}
+		result, err = loader.StopBySetting()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, r := range result {
+			if !r.Skipped || r.Error != nil {
+				t.Fatal("stopping a stopped module should be skipped", r)
+			}
+		}
+		if fmt.Sprint(record) != "[first second first second]" {
+			t.Fatal("each module should start and stop exactly once per cycle", record)
+		}
+	}
+}
+
+func TestStopBySettingStableOrder(t *testing.T) {
+	finished := &sync.Map{}
+	starters := []Starter{
+		&tiered{name: "third", priority: 3, finished: finished},
+		&tiered{name: "first", priority: 2, finished: finished},
+		&tiered{name: "second", priority: 2, finished: finished},
+	}
+	for i := 0; i < 20; i++ {
+		loader := NewStarterLoader(starters)
+		if err := loader.Start(); err != nil {
+			t.Fatal(err)
+		}
+		result, err := loader.StopBySetting()
+		if err != nil {
+			t.Fatal(err)
+		}
+		names := coll.SliceCollect(result, func(item *StopResult) string {
+			return item.StarterName
+		})
+		if fmt.Sprint(names) != "[first second third]" {
+			t.Fatal("equal priorities should keep registration order", names)
+		}
+	}
+}
+
+func TestValidatePriorities(t *testing.T) {
+	finished := &sync.Map{}
+	loader := NewStarterLoader([]Starter{
+		&tiered{name: "redis", priority: 2, finished: finished},
+		&tiered{name: "gin", priority: 0, finished: finished},
+		&tiered{name: "gorm", priority: 2, finished: finished},
+	})
+	err := loader.ValidatePriorities()
+	if err == nil || !strings.Contains(err.Error(), "2: redis, gorm") {
+		t.Fatal("colliding priorities should be reported", err)
+	}
+	loader = NewStarterLoader([]Starter{
+		&tiered{name: "redis", priority: 2, finished: finished},
+		&tiered{name: "gin", priority: 0, finished: finished},
+	})
+	if err = loader.ValidatePriorities(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDisabledStarter(t *testing.T) {
+	var record []string
+	loader := NewStarterLoader([]Starter{
+		&toggled{recorder: recorder{name: "metrics", record: &record}, enabled: false},
+		&toggled{recorder: recorder{name: "redis", record: &record}, enabled: true},
+		&recorder{name: "gin", record: &record},
+	})
+	if err := loader.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if err := loader.StartStarter("metrics"); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(record) != "[redis gin]" {
+		t.Fatal("disabled module should never start", record)
+	}
+	if status, _ := loader.GetStatus("metrics"); status != StarterStatusNotStarted {
+		t.Fatal("disabled module should stay not started", status)
+	}
+	if fmt.Sprint(loader.DisabledStarters()) != "[metrics]" {
+		t.Fatal("unexpected disabled starters", loader.DisabledStarters())
+	}
+	if fmt.Sprint(loader.StartedStarters()) != "[redis gin]" {
+		t.Fatal("unexpected started starters", loader.StartedStarters())
+	}
+}
+
+func TestInitErrorHandler(t *testing.T) {
+	var record []string
+	loader := NewStarterLoader([]Starter{
+		&recorder{name: "first", record: &record},
+		&initFailing{recorder: recorder{name: "second", record: &record}},
+		&recorder{name: "third", record: &record},
+	})
+	err := loader.Start()
+	if err == nil || !strings.Contains(err.Error(), "second init failed: wiring failed") {
+		t.Fatal("init error should fail the start", err)
+	}
+	if status, _ := loader.GetStatus("second"); status == StarterStatusStarted {
+		t.Fatal("module with failed init should not be started")
+	}
+	if fmt.Sprint(loader.StartedStarters()) != "[first]" {
+		t.Fatal("start should abort after the init failure", loader.StartedStarters())
+	}
+}
+
+func TestInitHandlerPanic(t *testing.T) {
+	var record []string
+	loader := NewStarterLoader([]Starter{&initPanicker{recorder: recorder{name: "wiring", record: &record}}})
+	err := loader.Start()
+	if err == nil || !strings.Contains(err.Error(), "wiring init panic: init boom") {
+		t.Fatal("init panic should be returned as start error", err)
+	}
+	if status, _ := loader.GetStatus("wiring"); status == StarterStatusStarted {
+		t.Fatal("module with panicking init should not be started")
+	}
+	if fmt.Sprint(record) != "[wiring wiring]" {
+		t.Fatal("module should be stopped after init panic", record)
+	}
+}
+
+func TestInitMaxWaitTime(t *testing.T) {
+	var record []string
+	loader := NewStarterLoader([]Starter{&slowInit{recorder: recorder{name: "wiring", record: &record}}})
+	current := time.Now()
+	err := loader.Start()
+	if err == nil || !strings.Contains(err.Error(), "wiring init exceeding the maximum wait time") {
+		t.Fatal("init timeout should be returned as start error", err)
+	}
+	if time.Since(current) > time.Millisecond*250 {
+		t.Fatal("start should not wait for the slow init", time.Since(current))
+	}
+	if status, _ := loader.GetStatus("wiring"); status == StarterStatusStarted {
+		t.Fatal("module with init timeout should not be started")
+	}
+	if fmt.Sprint(record) != "[wiring wiring]" {
+		t.Fatal("module should be stopped after init timeout", record)
+	}
+	loader = NewStarterLoader([]Starter{&slowInit{recorder: recorder{name: "wiring", record: &record}, warnOnly: true}})
+	if err = loader.Start(); err != nil {
+		t.Fatal("init timeout should only warn", err)
+	}
+	if status, _ := loader.GetStatus("wiring"); status != StarterStatusStarted {
+		t.Fatal("module should be started when init timeout only warns", status)
+	}
+}
+
+func TestIsAllStarted(t *testing.T) {
+	empty := NewStarterLoader(nil)
+	if empty.IsAllStarted() || empty.IsAnyStarted() {
+		t.Fatal("empty loader should report nothing started")
+	}
+	var record []string
+	loader := NewStarterLoader([]Starter{
+		&recorder{name: "first", record: &record},
+		&recorder{name: "second", record: &record},
+		&toggled{recorder: recorder{name: "metrics", record: &record}, enabled: false},
+	})
+	if loader.IsAllStarted() || loader.IsAnyStarted() {
+		t.Fatal("nothing should be started yet")
+	}
+	_ = loader.StartStarter("first")
+	if loader.IsAllStarted() || !loader.IsAnyStarted() {
+		t.Fatal("loader should be partially started")
+	}
+	_ = loader.Start()
+	if !loader.IsAllStarted() || !loader.IsAnyStarted() {
+		t.Fatal("loader should be fully started, ignoring disabled modules")
+	}
+}
+
+func TestStopReverse(t *testing.T) {
+	var record []string
+	loader := NewStarterLoader([]Starter{
+		&prioritized{recorder: recorder{name: "gin", record: &record}, priority: 2},
+		&prioritized{recorder: recorder{name: "redis", record: &record}, priority: 0},
+		&prioritized{recorder: recorder{name: "gorm", record: &record}, priority: 1},
+		&recorder{name: "idle", record: &record},
+	})
+	if err := loader.StartBySetting(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loader.StopStarter("idle", time.Second); err != nil {
+		t.Fatal(err)
+	}
+	started := fmt.Sprint(record[:3])
+	record = nil
+	result, err := loader.StopReverse(time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if started != "[redis gorm gin]" || fmt.Sprint(record) != "[gin gorm redis]" {
+		t.Fatal("stop order should be the reverse of start order", started, record)
+	}
+	if len(result) != 3 {
+		t.Fatal("only started modules should be stopped", result)
+	}
+}
+
+func TestClone(t *testing.T) {
+	var record []string
+	original := NewStarterLoaderWith(WithDefaultStopWait(time.Second), WithStarters(
+		&recorder{name: "first", record: &record},
+		&recorder{name: "second", record: &record},
+	))
+	_ = original.StartStarter("first")
+	cloned := original.Clone()
+	if status, _ := cloned.GetStatus("first"); status != StarterStatusNotStarted {
+		t.Fatal("cloned module should not be started", status)
+	}
+	if err := cloned.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(original.StartedStarters()) != "[first]" {
+		t.Fatal("original statuses should be untouched", original.StartedStarters())
+	}
+	if err := cloned.AddStarter(&recorder{name: "third", record: &record}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := original.GetStatus("third"); err == nil {
+		t.Fatal("clone should not share the module list")
+	}
+	if cloned.defaultStopWait != time.Second {
+		t.Fatal("loader settings should be copied")
+	}
+}
+
+func TestCloneHooks(t *testing.T) {
+	original := NewStarterLoader([]Starter{&sleeper{name: "first"}})
+	original.signals = make(chan os.Signal, 1)
+	var calls []string
+	original.OnBeforeStart(func(starterName string) {
+		calls = append(calls, "before:"+starterName)
+	})
+	cloned := original.Clone()
+	cloned.OnAfterStart(func(starterName string, instance interface{}, err error) {
+		calls = append(calls, "after:"+starterName)
+	})
+	if err := cloned.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(calls) != "[before:first after:first]" {
+		t.Fatal("clone should fire the copied hooks", calls)
+	}
+	calls = nil
+	if err := original.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(calls) != "[before:first]" {
+		t.Fatal("hooks registered on the clone should not fire on the original", calls)
+	}
+	if cloned.signals == nil || cloned.signals == original.signals {
+		t.Fatal("clone should have its own signal channel")
+	}
+}
+
+func TestReset(t *testing.T) {
+	var record []string
+	loader := NewStarterLoader([]Starter{&recorder{name: "first", record: &record}, &recorder{name: "second", record: &record}})
+	_ = loader.Start()
+	previous, _ := loader.GetInstance("first")
+	if err := loader.Reset(); err == nil {
+		t.Fatal("reset should fail while modules are started")
+	}
+	_, _ = loader.Stop(time.Second)
+	if err := loader.Reset(); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"first", "second"} {
+		if status, _ := loader.GetStatus(name); status != StarterStatusNotStarted {
+			t.Fatal("reset module should be not started", name, status)
+		}
+		if _, err := loader.GetInstance(name); err == nil {
+			t.Fatal("reset module should have no instance", name)
+		}
+	}
+	if err := loader.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if instance, _ := loader.GetInstance("first"); instance == nil || instance == previous {
+		t.Fatal("restarted module should have a fresh instance", instance)
+	}
+}
+
+func TestStopBySettingSkipsNotStarted(t *testing.T) {
+	var record []string
+	starters := []Starter{
+		&recorder{name: "first", record: &record},
+		&recorder{name: "second", record: &record},
+		&recorder{name: "third", record: &record},
+	}
+	loader := NewStarterLoader(starters)
+	_ = loader.StartStarter("first")
+	_ = loader.StartStarter("third")
+	result, err := loader.StopBySetting()
+	if err != nil {
+		t.Fatal(err)
+	}
+	summary := SummarizeStopResults(result)
+	if summary.Total != 3 || summary.Skipped != 1 || summary.Failed != 0 || summary.Error != nil {
+		t.Fatalf("not started module should only be skipped %+v", summary)
+	}
+	if !result[1].Skipped || result[1].StarterName != "second" || result[1].Error != nil {
+		t.Fatalf("unexpected skipped result %+v", result[1])
+	}
+
+	loader = NewStarterLoaderWith(WithOmitSkippedStops(true), WithStarters(starters...))
+	_ = loader.StartStarter("first")
+	_ = loader.StartStarter("third")
+	result, err = loader.StopBySetting()
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := coll.SliceCollect(result, func(item *StopResult) string {
+		return item.StarterName
+	})
+	if fmt.Sprint(names) != "[first third]" {
+		t.Fatal("skipped modules should be omitted", names)
+	}
+}
+
+func TestStartNewStarters(t *testing.T) {
+	var record []string
+	loader := NewStarterLoader([]Starter{&recorder{name: "first", record: &record}, &recorder{name: "second", record: &record}})
+	if err := loader.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loader.StopStarter("second", time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if err := loader.AddStarter(&dependent{name: "third", dependsOn: []string{"first"}, record: &record}); err != nil {
+		t.Fatal(err)
+	}
+	record = nil
+	if err := loader.StartNewStarters(); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(record) != "[third]" {
+		t.Fatal("only the newly added module should start", record)
+	}
+	if status, _ := loader.GetStatus("second"); status != StarterStatusStopped {
+		t.Fatal("stopped module should stay stopped", status)
+	}
+}
+
+// forcible 优雅停机超时后可强制停止的测试模块
+type forcible struct {
+	forceErr error
+	forced   bool
+}
+
+func (f *forcible) Setting() *Setting {
+	return NewSetting("forcible", 0, false, time.Millisecond*20, nil)
+}
+
+func (f *forcible) Start() (interface{}, error) {
+	return f, nil
+}
+
+func (f *forcible) Stop(maxWaitTime time.Duration) (gracefully bool, stopped bool, err error) {
+	time.Sleep(maxWaitTime)
+	return false, false, errors.New("timeout")
+}
+
+func (f *forcible) ForceStop() error {
+	f.forced = true
+	return f.forceErr
+}
+
+func TestForceStop(t *testing.T) {
+	module := &forcible{}
+	loader := NewStarterLoader([]Starter{module, &gorm{}})
+	_ = loader.Start()
+	result, err := loader.StopBySetting()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !module.forced || !result[0].Forced || !result[0].Stopped || result[0].Gracefully {
+		t.Fatalf("module should be force stopped %+v", result[0])
+	}
+	if result[1].Forced {
+		t.Fatal("graceful module should not be forced")
+	}
+	if status, _ := loader.GetStatus("forcible"); status != StarterStatusStopped {
+		t.Fatal("force stopped module should be stopped", status)
+	}
+
+	module = &forcible{forceErr: errors.New("still running")}
+	loader = NewStarterLoader([]Starter{module})
+	_ = loader.Start()
+	stopResult, _ := loader.StopStarter("forcible", 0)
+	if !stopResult.Forced || stopResult.Stopped || !strings.Contains(stopResult.Error.Error(), "force stop failed: still running") {
+		t.Fatalf("failed force stop should be reported %+v", stopResult)
+	}
+	if status, _ := loader.GetStatus("forcible"); status != StarterStatusStarted {
+		t.Fatal("module should keep running after a failed force stop", status)
+	}
+}
+
+func TestStartAsync(t *testing.T) {
+	loader := NewStarterLoader([]Starter{&sleeper{name: "slow", delay: time.Millisecond * 50}, &sleeper{name: "fast"}})
+	done := loader.StartAsync()
+	// 启动完成前可继续执行其他工作
+	if loader.IsAllStarted() {
+		t.Fatal("start should run in the background")
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("async start should complete")
+	}
+	if !loader.IsAllStarted() {
+		t.Fatal("all modules should be started once the channel delivers")
+	}
+	if _, ok := <-done; ok {
+		t.Fatal("channel should be closed after delivering the result")
+	}
+
+	loader = NewStarterLoader([]Starter{&failing{name: "broken"}})
+	if err := <-loader.StartAsync(); err == nil {
+		t.Fatal("start error should be delivered")
+	}
+}
+
+func TestStopBySettingTimeoutListsUnfinished(t *testing.T) {
+	loader := NewStarterLoader([]Starter{&lingering{name: "mq", delay: time.Second}, &sleeper{name: "cache"}})
+	if err := loader.Start(); err != nil {
+		t.Fatal(err)
+	}
+	result, err := loader.StopBySetting(time.Millisecond * 100)
+	if err == nil || !strings.Contains(err.Error(), "mq") {
+		t.Fatal("timeout error should list the unfinished module", err)
+	}
+	if strings.Contains(err.Error(), "cache") {
+		t.Fatal("finished module should not be listed", err)
+	}
+	if len(result) != 2 || !result[0].TimedOut || result[1].TimedOut {
+		t.Fatalf("unexpected stop result %+v %+v", result[0], result[1])
+	}
+}
+
+func TestResultIndex(t *testing.T) {
+	finished := &sync.Map{}
+	loader := NewStarterLoader([]Starter{
+		&tiered{name: "web", priority: 20, finished: finished},
+		&tiered{name: "mq", priority: 0, finished: finished},
+		&tiered{name: "db", priority: 10, finished: finished},
+	})
+	started, err := loader.StartDetailed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, result := range started {
+		if result.Index != i {
+			t.Fatalf("unexpected start index %+v", result)
+		}
+	}
+	stopped, err := loader.StopBySetting()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]int{"web": 0, "mq": 1, "db": 2}
+	if stopped[0].StarterName != "mq" || stopped[2].StarterName != "web" {
+		t.Fatal("results should follow stop order", stopped[0].StarterName, stopped[2].StarterName)
+	}
+	for _, result := range stopped {
+		if result.Index != expected[result.StarterName] {
+			t.Fatalf("stop index should match registration order %+v", result)
+		}
+	}
+}
+
+func TestNewSettingsAlias(t *testing.T) {
+	setting, deprecated := NewSetting("redis", 3, true, time.Second, nil), NewSettings("redis", 3, true, time.Second, nil)
+	if !reflect.DeepEqual(setting, deprecated) {
+		t.Fatal("NewSettings should forward to NewSetting", setting, deprecated)
+	}
+}
+
+func TestStopErrorWrapping(t *testing.T) {
+	original := errors.New("connection reset")
+	broken := NewFuncStarter("mq", nil, func(maxWaitTime time.Duration) (gracefully, stopped bool, err error) {
+		return false, false, original
+	})
+	loader := NewStarterLoader([]Starter{broken})
+	if err := loader.Start(); err != nil {
+		t.Fatal(err)
+	}
+	result, err := loader.StopStarter("mq", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Error.Error() != "mq: connection reset" {
+		t.Fatal("stop error should be prefixed with the module name", result.Error)
+	}
+	if errors.Unwrap(result.Error) != original || !errors.Is(result.Error, original) {
+		t.Fatal("original error should be recoverable", result.Error)
+	}
+}
+
+// unset 未设置Setting的测试模块
+type unset struct {
+	stopped bool
+}
+
+func (u *unset) Setting() *Setting {
+	return nil
+}
+
+func (u *unset) Start() (interface{}, error) {
+	return u, nil
+}
+
+func (u *unset) Stop(maxWaitTime time.Duration) (gracefully bool, stopped bool, err error) {
+	u.stopped = maxWaitTime == time.Second*2
+	return true, true, nil
+}
+
+func TestNilSetting(t *testing.T) {
+	module := &unset{}
+	loader := NewStarterLoaderWith(WithDefaultStopWait(time.Second*2), WithStarters(module, &sleeper{name: "gin"}))
+	if err := loader.StartBySetting(); err != nil {
+		t.Fatal("nil setting should only warn by default", err)
+	}
+	if fmt.Sprint(loader.StartedStarters()) != "[unnamed-1 gin]" {
+		t.Fatal("unexpected started starters", loader.StartedStarters())
+	}
+	result, err := loader.StopBySetting()
+	if err != nil {
+		t.Fatal("StopBySetting should accept nil setting", err)
+	}
+	if len(result) != 2 || !result[0].Stopped || !module.stopped {
+		t.Fatal("nil setting module should be stopped with the default setting", result)
+	}
+	loader = NewStarterLoaderWith(WithRequireSettings(true), WithStarters(&unset{}, &sleeper{name: "gin"}))
+	if err = loader.Start(); err == nil || !strings.Contains(err.Error(), "no setting") {
+		t.Fatal("nil setting should fail when settings are required", err)
+	}
+	loader = NewStarterLoaderWith(WithRequireSettings(true), WithStarters(&sleeper{}))
+	if err = loader.Start(); err != nil {
+		t.Fatal("requiring settings should not require names", err)
+	}
+}
+
+func TestStopBySettingWithOverride(t *testing.T) {
+	waited := make(map[string]time.Duration)
+	var mu sync.Mutex
+	stop := func(starterName string) func(maxWaitTime time.Duration) (gracefully, stopped bool, err error) {
+		return func(maxWaitTime time.Duration) (gracefully, stopped bool, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			waited[starterName] = maxWaitTime
+			return true, true, nil
+		}
+	}
+	loader := NewStarterLoader([]Starter{
+		NewFuncStarter("redis", nil, stop("redis"), WithFuncStop(3, true, time.Second*3)),
+		NewFuncStarter("gorm", nil, stop("gorm"), WithFuncStop(20, true, time.Second)),
+	})
+	if err := loader.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loader.StopBySettingWithOverride(map[string]time.Duration{"mongo": time.Second}); err == nil {
+		t.Fatal("unknown override name should fail")
+	}
+	if loader.ShutdownContext().Err() != nil {
+		t.Fatal("rejected override should not cancel the shutdown context")
+	}
+	if _, err := loader.StopBySettingWithOverride(map[string]time.Duration{"redis": time.Second * 30}); err != nil {
+		t.Fatal(err)
+	}
+	if loader.ShutdownContext().Err() == nil {
+		t.Fatal("shutdown context should be cancelled once stopping begins")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if waited["redis"] != time.Second*30 || waited["gorm"] != time.Second {
+		t.Fatal("override should only apply to redis", waited)
+	}
+}
+
+func TestInsertStarter(t *testing.T) {
+	var record []string
+	loader := NewStarterLoader([]Starter{&recorder{name: "gorm", record: &record}, &recorder{name: "gin", record: &record}})
+	if err := loader.InsertStarter(3, &recorder{name: "nacos", record: &record}); err == nil {
+		t.Fatal("out of range index should fail")
+	}
+	if err := loader.InsertStarter(0, &recorder{name: "gin", record: &record}); err == nil {
+		t.Fatal("duplicate name should be rejected")
+	}
+	if err := loader.InsertStarter(0, &recorder{name: "config", record: &record}, &recorder{name: "redis", record: &record}); err != nil {
+		t.Fatal(err)
+	}
+	result, err := loader.StartDetailed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(record) != "[config redis gorm gin]" {
+		t.Fatal("inserted starters should start first", record)
+	}
+	if result[3].StarterName != "gin" || result[3].Index != 3 {
+		t.Fatalf("index should follow the inserted position %+v", result[3])
+	}
+}
+
+func TestStopTimedOut(t *testing.T) {
+	loader := NewStarterLoader([]Starter{&redis{}, &gin{}})
+	if err := loader.Start(); err != nil {
+		t.Fatal(err)
+	}
+	result, err := loader.Stop(time.Millisecond * 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result[0].TimedOut || !errors.Is(result[0].Error, ErrStopTimeout) || !strings.Contains(result[0].Error.Error(), "timeout") {
+		t.Fatalf("module exceeding its window should be marked timed out %+v", result[0])
+	}
+	if original := errors.Unwrap(result[0].Error); original == nil || original.Error() != "timeout" {
+		t.Fatal("timed out error should unwrap to the module error, got", original)
+	}
+	if result[1].TimedOut || errors.Is(result[1].Error, ErrStopTimeout) {
+		t.Fatalf("module reporting its own error should not be marked timed out %+v", result[1])
+	}
+}
+
+func TestStartStarters(t *testing.T) {
+	loader := NewStarterLoader([]Starter{&redis{}, &gorm{}, &gin{}, &failing{name: "broken"}})
+	if err := loader.StartStarters("gorm", "mongo"); err == nil {
+		t.Fatal("unknown starter name should fail")
+	}
+	if len(loader.StartedStarters()) != 0 {
+		t.Fatal("nothing should be started when a name is unknown", loader.StartedStarters())
+	}
+	if err := loader.StartStarters("gorm", "gin"); err != nil {
+		t.Fatal(err)
+	}
+	for _, starterName := range []string{"gorm", "gin"} {
+		if status, _ := loader.GetStatus(starterName); status != StarterStatusStarted {
+			t.Fatal(starterName, "should be started", status)
+		}
+	}
+	err := loader.StartStarters("broken", "unnamed-1")
+	if err == nil || !strings.Contains(err.Error(), "start broken failed") {
+		t.Fatal("failed starter should be reported", err)
+	}
+	if status, _ := loader.GetStatus("unnamed-1"); status != StarterStatusNotStarted {
+		t.Fatal("starters after the failure should not be started", status)
+	}
+}
@@ -0,0 +1,40 @@
+package parent
+
+import (
+	"errors"
+	"path"
+)
+
+// StartMatching 按依赖顺序启动名称匹配pattern的未启动模块 其他模块不受影响
+// pattern使用path.Match的语法 如web-*，没有任何模块匹配时返回异常
+// 注意 未匹配的依赖模块不会被启动
+func (s *StarterLoader) StartMatching(pattern string) error {
+	defer s.Mutex.Unlock()
+	s.Mutex.Lock()
+	if s.starters.isEmpty() {
+		return errors.New("miss starters")
+	}
+	ordered, err := dependencyOrder(*s.starters)
+	if err != nil {
+		return err
+	}
+	matched := make([]*starterWrapper, 0)
+	for _, wrapper := range ordered {
+		ok, err := path.Match(pattern, wrapper.getStarterName())
+		if err != nil {
+			return err
+		}
+		if ok {
+			matched = append(matched, wrapper)
+		}
+	}
+	if len(matched) == 0 {
+		return errors.New("no starter matches pattern: " + pattern)
+	}
+	for _, wrapper := range matched {
+		if err = s.start(wrapper); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package parent
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestStartMatching(t *testing.T) {
+	loader := NewStarterLoader([]Starter{
+		&sleeper{name: "web-api"},
+		&sleeper{name: "db-main"},
+		&sleeper{name: "web-admin"},
+		&sleeper{name: "webhook"},
+	})
+	if err := loader.StartMatching("web-*"); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(loader.StartedStarters()) != "[web-api web-admin]" {
+		t.Fatal("only matching starters should be started", loader.StartedStarters())
+	}
+	if err := loader.StartMatching("cache-*"); err == nil {
+		t.Fatal("pattern matching nothing should fail")
+	}
+	if err := loader.StartMatching("[web"); err == nil {
+		t.Fatal("malformed pattern should fail")
+	}
+	if len(loader.StartedStarters()) != 2 {
+		t.Fatal("failed matching should not start anything", loader.StartedStarters())
+	}
+}
@@ -0,0 +1,68 @@
+package parent
+
+import "time"
+
+// MetricsRecorder 可选的启动/停止耗时指标记录 可用于对接Prometheus、OpenTelemetry等指标系统
+// 回调在启动/停止流程中同步执行，实现应当快速返回
+type MetricsRecorder interface {
+	// RecordStart 模块完成一次启动(包含重试及初始化) err不为nil表示启动失败
+	RecordStart(starterName string, duration time.Duration, err error)
+	// RecordStop 模块完成一次停止 未启动而跳过停止的模块不记录
+	RecordStop(starterName string, duration time.Duration, result *StopResult)
+}
+
+// 不记录任何指标的默认实现
+type noopMetricsRecorder struct{}
+
+func (noopMetricsRecorder) RecordStart(string, time.Duration, error) {}
+
+func (noopMetricsRecorder) RecordStop(string, time.Duration, *StopResult) {}
+
+// 获取加载器使用的指标记录
+func (s *StarterLoader) metrics() MetricsRecorder {
+	if s.metricsRecorder != nil {
+		return s.metricsRecorder
+	}
+	return noopMetricsRecorder{}
+}
+
+// 记录模块启动的指标 指标记录panic时不影响启动流程
+func (s *StarterLoader) recordStart(starterName string, duration time.Duration, err error) {
+	s.invokeCallback(starterName, "metrics recorder", func() {
+		s.metrics().RecordStart(starterName, duration, err)
+	})
+}
+
+// 记录模块停止的指标 指标记录panic时不影响停止流程
+func (s *StarterLoader) recordStop(starterName string, duration time.Duration, result *StopResult) {
+	s.invokeCallback(starterName, "metrics recorder", func() {
+		s.metrics().RecordStop(starterName, duration, result)
+	})
+}
+
+// 依次调用的多个指标记录
+type metricsRecorders []MetricsRecorder
+
+func (m metricsRecorders) RecordStart(starterName string, duration time.Duration, err error) {
+	for _, recorder := range m {
+		recorder.RecordStart(starterName, duration, err)
+	}
+}
+
+func (m metricsRecorders) RecordStop(starterName string, duration time.Duration, result *StopResult) {
+	for _, recorder := range m {
+		recorder.RecordStop(starterName, duration, result)
+	}
+}
+
+// AddMetricsRecorder 追加一个指标记录 与已设置的指标记录同时生效，适用于在加载器创建后接入指标系统
+// 正在进行启动/停止时将阻塞直到其完成
+func (s *StarterLoader) AddMetricsRecorder(recorder MetricsRecorder) {
+	defer s.Mutex.Unlock()
+	s.Mutex.Lock()
+	if s.metricsRecorder == nil {
+		s.metricsRecorder = recorder
+		return
+	}
+	s.metricsRecorder = metricsRecorders{s.metricsRecorder, recorder}
+}
@@ -0,0 +1,85 @@
+// Package metrics 提供模块加载器的Prometheus指标 独立为子模块以避免核心模块依赖Prometheus
+package metrics
+
+import (
+	"github.com/golang-acexy/starter-parent/parent"
+	"github.com/prometheus/client_golang/prometheus"
+	"sync"
+	"time"
+)
+
+const (
+	stateStarted = "started"
+	stateStopped = "stopped"
+	stateFailed  = "failed"
+
+	operationStart = "start"
+	operationStop  = "stop"
+)
+
+// collector 基于MetricsRecorder采集模块启动/停止指标的Prometheus采集器
+type collector struct {
+	loader    *parent.StarterLoader
+	modules   *prometheus.Desc
+	durations *prometheus.HistogramVec
+	// 最近一次启动/停止失败的模块
+	mu     sync.Mutex
+	failed map[string]bool
+}
+
+// NewCollector 创建模块加载器的Prometheus采集器 并将其作为MetricsRecorder追加到加载器
+//
+//	starter_modules{state="started|stopped|failed"} 已启动、未运行、最近一次启动/停止失败的模块数
+//	starter_duration_seconds{operation="start|stop"} 模块启动/停止耗时的分布
+//
+// 指标名称固定，同一Registry中仅可注册一个加载器的采集器；每次调用都会创建新的采集器并追加MetricsRecorder，同一加载器应仅调用一次
+func NewCollector(loader *parent.StarterLoader) prometheus.Collector {
+	c := &collector{
+		loader: loader,
+		modules: prometheus.NewDesc("starter_modules",
+			"Number of modules by state, stopped counts every module not running.",
+			[]string{"state"}, nil),
+		durations: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "starter_duration_seconds",
+			Help:    "Duration of module start and stop.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"operation"}),
+		failed: make(map[string]bool),
+	}
+	loader.AddMetricsRecorder(c)
+	return c
+}
+
+func (c *collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.modules
+	c.durations.Describe(ch)
+}
+
+func (c *collector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	failed := 0
+	for _, v := range c.failed {
+		if v {
+			failed++
+		}
+	}
+	c.mu.Unlock()
+	ch <- prometheus.MustNewConstMetric(c.modules, prometheus.GaugeValue, float64(len(c.loader.StartedStarters())), stateStarted)
+	ch <- prometheus.MustNewConstMetric(c.modules, prometheus.GaugeValue, float64(len(c.loader.StoppedStarters())), stateStopped)
+	ch <- prometheus.MustNewConstMetric(c.modules, prometheus.GaugeValue, float64(failed), stateFailed)
+	c.durations.Collect(ch)
+}
+
+func (c *collector) RecordStart(starterName string, duration time.Duration, err error) {
+	c.durations.WithLabelValues(operationStart).Observe(duration.Seconds())
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.failed[starterName] = err != nil
+}
+
+func (c *collector) RecordStop(starterName string, duration time.Duration, result *parent.StopResult) {
+	c.durations.WithLabelValues(operationStop).Observe(duration.Seconds())
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.failed[starterName] = result.Error != nil || !result.Stopped
+}
@@ -0,0 +1,50 @@
+package metrics
+
+import (
+	"errors"
+	"github.com/golang-acexy/starter-parent/parent"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"testing"
+	"time"
+)
+
+func TestCollector(t *testing.T) {
+	loader := parent.NewStarterLoader([]parent.Starter{
+		parent.NewFuncStarter("gorm", nil, nil),
+		parent.NewFuncStarter("gin", nil, func(maxWaitTime time.Duration) (gracefully, stopped bool, err error) {
+			return false, false, errors.New("something error")
+		}),
+		parent.NewFuncStarter("redis", nil, nil),
+	})
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(NewCollector(loader))
+	if err := loader.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loader.StopStarters(time.Second, "gorm", "gin"); err != nil {
+		t.Fatal(err)
+	}
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	gathered := make(map[string]*dto.MetricFamily)
+	for _, family := range families {
+		gathered[family.GetName()] = family
+	}
+	states := make(map[string]float64)
+	for _, metric := range gathered["starter_modules"].GetMetric() {
+		states[metric.GetLabel()[0].GetValue()] = metric.GetGauge().GetValue()
+	}
+	if states["started"] != 2 || states["stopped"] != 1 || states["failed"] != 1 {
+		t.Fatal("unexpected module states", states)
+	}
+	counts := make(map[string]uint64)
+	for _, metric := range gathered["starter_duration_seconds"].GetMetric() {
+		counts[metric.GetLabel()[0].GetValue()] = metric.GetHistogram().GetSampleCount()
+	}
+	if counts["start"] != 3 || counts["stop"] != 2 {
+		t.Fatal("unexpected duration samples", counts)
+	}
+}
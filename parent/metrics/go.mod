@@ -0,0 +1,25 @@
+module github.com/golang-acexy/starter-parent/parent/metrics
+
+go 1.21.0
+
+toolchain go1.21.5
+
+require (
+	github.com/golang-acexy/starter-parent v0.0.0-20261015024936-d801470df76d
+	github.com/prometheus/client_golang v1.19.1
+	github.com/prometheus/client_model v0.5.0
+)
+
+require (
+	github.com/acexy/golang-toolkit v0.0.38 // indirect
+	github.com/beorn7/perks v1.0.1 // indirect
+	github.com/cespare/xxhash/v2 v2.2.0 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/prometheus/common v0.48.0 // indirect
+	github.com/prometheus/procfs v0.12.0 // indirect
+	github.com/sirupsen/logrus v1.9.3 // indirect
+	github.com/timandy/routine v1.1.4 // indirect
+	golang.org/x/sys v0.28.0 // indirect
+	google.golang.org/protobuf v1.33.0 // indirect
+	gopkg.in/natefinch/lumberjack.v2 v2.2.1 // indirect
+)
@@ -0,0 +1,54 @@
+package parent
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// timings 记录回调的指标记录实现
+type timings struct {
+	mu     sync.Mutex
+	starts map[string]time.Duration
+	stops  map[string]*StopResult
+	errs   map[string]error
+}
+
+func (m *timings) RecordStart(starterName string, duration time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.starts[starterName] = duration
+	m.errs[starterName] = err
+}
+
+func (m *timings) RecordStop(starterName string, duration time.Duration, result *StopResult) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.stops[starterName] = result
+}
+
+func TestWithMetricsRecorder(t *testing.T) {
+	recorder := &timings{starts: map[string]time.Duration{}, stops: map[string]*StopResult{}, errs: map[string]error{}}
+	loader := NewStarterLoaderWith(WithMetricsRecorder(recorder), WithStarters(
+		&sleeper{name: "slow", delay: time.Millisecond * 30},
+		&failing{name: "broken"},
+		&sleeper{name: "idle"},
+	))
+	_ = loader.StartStarter("slow")
+	_ = loader.StartStarter("broken")
+	if d := recorder.starts["slow"]; d < time.Millisecond*30 || d > time.Second {
+		t.Fatal("implausible start duration", d)
+	}
+	if recorder.errs["broken"] == nil {
+		t.Fatal("start failure should be recorded with its error")
+	}
+	if _, err := loader.Stop(time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if result := recorder.stops["slow"]; result == nil || !result.Stopped || result.Duration > time.Second {
+		t.Fatal("stop should be recorded", result)
+	}
+	if _, ok := recorder.stops["idle"]; ok {
+		t.Fatal("skipped stop should not be recorded")
+	}
+}
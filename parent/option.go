@@ -0,0 +1,140 @@
+package parent
+
+import (
+	"github.com/acexy/golang-toolkit/logger"
+	"github.com/sirupsen/logrus"
+	"time"
+)
+
+// LoaderOption 模块加载器的创建选项
+type LoaderOption func(loader *StarterLoader)
+
+// WithLogger 设置加载器使用的日志 未设置时使用toolkit的全局日志
+func WithLogger(l *logrus.Logger) LoaderOption {
+	return func(loader *StarterLoader) {
+		loader.logger = l
+	}
+}
+
+// WithDefaultStopWait 设置默认的停止等待时间 当停止时未指定或模块未设置等待时间时使用
+func WithDefaultStopWait(d time.Duration) LoaderOption {
+	return func(loader *StarterLoader) {
+		loader.defaultStopWait = d
+	}
+}
+
+// WithStrictNames 设置是否严格校验模块名称 开启后出现重复的模块名称时添加及启动模块将返回异常
+func WithStrictNames(strict bool) LoaderOption {
+	return func(loader *StarterLoader) {
+		loader.strictNames = strict
+	}
+}
+
+// WithRequireNames 设置是否要求模块设置名称 开启后存在未命名的模块时添加及启动模块将返回异常
+// 未开启时为未命名的模块分配唯一的名称 如unnamed-1，可通过该名称查找模块
+func WithRequireNames(require bool) LoaderOption {
+	return func(loader *StarterLoader) {
+		loader.requireNames = require
+	}
+}
+
+// WithRequireSettings 设置是否要求模块设置Setting 开启后存在Setting为nil的模块时添加及启动模块将返回异常
+// 未开启时输出警告日志，启动/停止时使用默认设置
+func WithRequireSettings(require bool) LoaderOption {
+	return func(loader *StarterLoader) {
+		loader.requireSettings = require
+	}
+}
+
+// WithMaxAsyncStops 设置按设置停止时允许异步停止的模块的最大并发数 小于等于0表示不限制
+func WithMaxAsyncStops(n int) LoaderOption {
+	return func(loader *StarterLoader) {
+		loader.maxAsyncStops = n
+	}
+}
+
+// WithMetricsRecorder 设置记录模块启动/停止耗时的指标记录
+func WithMetricsRecorder(recorder MetricsRecorder) LoaderOption {
+	return func(loader *StarterLoader) {
+		loader.metricsRecorder = recorder
+	}
+}
+
+// WithTracer 设置链路追踪 每次模块启动/停止将分别创建名为starter.start/<name>、starter.stop/<name>的span
+func WithTracer(tracer Tracer) LoaderOption {
+	return func(loader *StarterLoader) {
+		loader.lifecycleTracer = tracer
+	}
+}
+
+// WithOmitSkippedStops 设置按设置停止时是否在结果中省略因未启动而跳过的模块 默认保留并标记为Skipped
+func WithOmitSkippedStops(omit bool) LoaderOption {
+	return func(loader *StarterLoader) {
+		loader.omitSkippedStops = omit
+	}
+}
+
+// WithDuplicateStartPolicy 设置通过StartStarter启动已启动的模块时的处理策略 默认为DuplicateStartIgnore
+func WithDuplicateStartPolicy(policy DuplicateStartPolicy) LoaderOption {
+	return func(loader *StarterLoader) {
+		loader.duplicateStartPolicy = policy
+	}
+}
+
+// WithSoftStopFraction 开启分阶段停止 首次以模块等待时间的fraction倍尝试停止，未能优雅停机时再以完整的等待时间重试
+// 两次尝试的结果记录在StopResult.Attempts中 fraction需在(0, 1)之间，否则不分阶段
+func WithSoftStopFraction(fraction float64) LoaderOption {
+	return func(loader *StarterLoader) {
+		loader.softStopFraction = fraction
+	}
+}
+
+// WithStopProgressSink 设置模块停止进度的接收方 实现了ProgressReporter的模块在停止过程中汇报的进度将转发给sink
+// sink在模块的停止流程中同步执行，实现应当快速返回
+func WithStopProgressSink(sink func(progress StopProgress)) LoaderOption {
+	return func(loader *StarterLoader) {
+		loader.progressSink = sink
+	}
+}
+
+// WithPhases 设置启动阶段的先后顺序 (适用于按阶段启动/停止模块)
+// 未设置的阶段按该阶段首个模块的注册顺序排在已设置的阶段之后
+func WithPhases(phases ...string) LoaderOption {
+	return func(loader *StarterLoader) {
+		loader.phases = phases
+	}
+}
+
+// WithStarters 设置加载器管理的模块 按传入顺序加载
+func WithStarters(starters ...Starter) LoaderOption {
+	return func(loader *StarterLoader) {
+		if len(starters) == 0 {
+			return
+		}
+		if loader.starters == nil {
+			loader.starters = &starterWrappers{}
+		}
+		for _, v := range starters {
+			wrapper := &starterWrapper{starter: v, index: len(*loader.starters)}
+			loader.assignName(wrapper)
+			*loader.starters = append(*loader.starters, wrapper)
+		}
+	}
+}
+
+// NewStarterLoaderWith 按选项创建一个模块加载器 每次调用均返回独立的加载器实例
+func NewStarterLoaderWith(opts ...LoaderOption) *StarterLoader {
+	loader := &StarterLoader{}
+	for _, opt := range opts {
+		opt(loader)
+	}
+	return loader
+}
+
+// 获取加载器使用的日志
+func (s *StarterLoader) log() *logrus.Logger {
+	if s.logger != nil {
+		return s.logger
+	}
+	return logger.Logrus()
+}
@@ -0,0 +1,262 @@
+package parent
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// unbounded 不设置停止等待时间的测试模块
+type unbounded struct {
+	waited time.Duration
+}
+
+func (u *unbounded) Setting() *Setting {
+	return NewSetting("unbounded", 0, false, 0, nil)
+}
+
+func (u *unbounded) Start() (interface{}, error) {
+	return u, nil
+}
+
+func (u *unbounded) Stop(maxWaitTime time.Duration) (gracefully bool, stopped bool, err error) {
+	u.waited = maxWaitTime
+	return true, true, nil
+}
+
+// concurrent 异步停止的测试模块 记录同时停止的模块数峰值
+type concurrent struct {
+	name    string
+	running *atomic.Int32
+	peak    *atomic.Int32
+}
+
+func (c concurrent) Setting() *Setting {
+	return NewSetting(c.name, 0, true, time.Second, nil)
+}
+
+func (c concurrent) Start() (interface{}, error) {
+	return c, nil
+}
+
+func (c concurrent) Stop(maxWaitTime time.Duration) (gracefully bool, stopped bool, err error) {
+	current := c.running.Add(1)
+	defer c.running.Add(-1)
+	for {
+		peak := c.peak.Load()
+		if current <= peak || c.peak.CompareAndSwap(peak, current) {
+			break
+		}
+	}
+	time.Sleep(time.Millisecond * 10)
+	return true, true, nil
+}
+
+// stubborn 停止需要一定耗时的测试模块 等待时间不足时超时返回未停止
+type stubborn struct {
+	drain time.Duration
+	waits []time.Duration
+}
+
+func (s *stubborn) Setting() *Setting {
+	return NewSetting("stubborn", 0, false, time.Second, nil)
+}
+
+func (s *stubborn) Start() (interface{}, error) {
+	return s, nil
+}
+
+func (s *stubborn) Stop(maxWaitTime time.Duration) (gracefully bool, stopped bool, err error) {
+	s.waits = append(s.waits, maxWaitTime)
+	if maxWaitTime < s.drain {
+		time.Sleep(maxWaitTime)
+		return false, false, errors.New("drain timeout")
+	}
+	time.Sleep(s.drain)
+	return true, true, nil
+}
+
+func TestWithStarters(t *testing.T) {
+	loader := NewStarterLoaderWith(WithStarters(&sleeper{name: "first"}, &sleeper{name: "second"}))
+	if err := loader.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if len(loader.StartedStarters()) != 2 {
+		t.Fatal("unexpected started starters", loader.StartedStarters())
+	}
+}
+
+func TestWithLogger(t *testing.T) {
+	var buffer bytes.Buffer
+	l := logrus.New()
+	l.SetOutput(&buffer)
+	l.SetLevel(logrus.TraceLevel)
+	loader := NewStarterLoaderWith(WithLogger(l), WithStarters(&sleeper{name: "logged"}))
+	if err := loader.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buffer.String(), "logged started successful") {
+		t.Fatal("injected logger not used", buffer.String())
+	}
+}
+
+func TestWithDefaultStopWait(t *testing.T) {
+	module := &unbounded{}
+	loader := NewStarterLoaderWith(WithDefaultStopWait(time.Second*2), WithStarters(module))
+	if err := loader.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loader.StopBySetting(); err != nil {
+		t.Fatal(err)
+	}
+	if module.waited != time.Second*2 {
+		t.Fatal("default stop wait not applied", module.waited)
+	}
+}
+
+func TestWithStrictNames(t *testing.T) {
+	loader := NewStarterLoaderWith(WithStrictNames(true), WithStarters(&sleeper{name: "redis"}, &sleeper{name: "redis"}))
+	err := loader.Start()
+	if err == nil || !strings.Contains(err.Error(), "redis") {
+		t.Fatal("duplicate names should fail in strict mode", err)
+	}
+	if len(loader.StartedStarters()) != 0 {
+		t.Fatal("nothing should be started")
+	}
+	loader = NewStarterLoaderWith(WithStrictNames(true), WithStarters(&sleeper{name: "redis"}))
+	if err = loader.AddStarter(&sleeper{name: "redis"}); err == nil {
+		t.Fatal("AddStarter should reject duplicate names in strict mode")
+	}
+	loader = NewStarterLoaderWith(WithStarters(&sleeper{name: "redis"}))
+	if err = loader.AddStarter(&sleeper{name: "redis"}); err != nil {
+		t.Fatal("duplicate names should only warn by default", err)
+	}
+	if err = loader.Start(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWithMaxAsyncStops(t *testing.T) {
+	var running, peak atomic.Int32
+	starters := make([]Starter, 0, 50)
+	for i := 0; i < 50; i++ {
+		starters = append(starters, concurrent{name: fmt.Sprintf("module-%d", i), running: &running, peak: &peak})
+	}
+	loader := NewStarterLoaderWith(WithMaxAsyncStops(4), WithStarters(starters...))
+	if err := loader.Start(); err != nil {
+		t.Fatal(err)
+	}
+	result, err := loader.StopBySetting(time.Second * 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range result {
+		if r == nil || !r.Stopped {
+			t.Fatal("all modules should be stopped", r)
+		}
+	}
+	if peak.Load() > 4 {
+		t.Fatal("async stop concurrency exceeded the limit", peak.Load())
+	}
+}
+
+func TestWithDuplicateStartPolicy(t *testing.T) {
+	loader := NewStarterLoaderWith(WithStarters(&sleeper{name: "redis"}))
+	if err := loader.StartStarter("redis"); err != nil {
+		t.Fatal(err)
+	}
+	if err := loader.StartStarter("redis"); err != nil {
+		t.Fatal("duplicate start should be ignored by default", err)
+	}
+	loader = NewStarterLoaderWith(WithDuplicateStartPolicy(DuplicateStartError), WithStarters(&sleeper{name: "redis"}))
+	if err := loader.StartStarter("redis"); err != nil {
+		t.Fatal(err)
+	}
+	err := loader.StartStarter("redis")
+	if err == nil || !strings.Contains(err.Error(), "already started") {
+		t.Fatal("duplicate start should fail with DuplicateStartError", err)
+	}
+	if status, _ := loader.GetStatus("redis"); status != StarterStatusStarted {
+		t.Fatal("module should stay started", status)
+	}
+}
+
+func TestWithSoftStopFraction(t *testing.T) {
+	module := &stubborn{drain: time.Millisecond * 500}
+	loader := NewStarterLoaderWith(WithSoftStopFraction(0.3), WithStarters(module))
+	if err := loader.Start(); err != nil {
+		t.Fatal(err)
+	}
+	result, err := loader.StopBySetting()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(module.waits) != 2 || module.waits[0] != time.Millisecond*300 || module.waits[1] != time.Second {
+		t.Fatal("stop should escalate from the soft window to the full window", module.waits)
+	}
+	attempts := result[0].Attempts
+	if len(attempts) != 2 || attempts[0].Gracefully || attempts[0].Error == nil || !attempts[1].Gracefully {
+		t.Fatalf("both attempts should be recorded %+v", attempts)
+	}
+	if !result[0].Stopped || !result[0].Gracefully || result[0].Error != nil {
+		t.Fatalf("module should be stopped gracefully after escalation %+v", result[0])
+	}
+}
+
+func TestUnnamedSequenceAfterRejectedAdd(t *testing.T) {
+	loader := NewStarterLoaderWith(WithStarters(&sleeper{}, &sleeper{name: "gin"}))
+	if err := loader.InsertStarter(3, &sleeper{}); err == nil {
+		t.Fatal("out of range index should fail")
+	}
+	if err := loader.InsertStarter(0, &sleeper{}, &sleeper{name: "gin"}); err == nil {
+		t.Fatal("duplicate name should be rejected")
+	}
+	if err := loader.AddStarter(&sleeper{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := loader.InsertStarter(0, &sleeper{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := loader.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(loader.StartedStarters()) != "[unnamed-3 unnamed-1 gin unnamed-2]" {
+		t.Fatal("rejected starters should not consume unnamed sequence", loader.StartedStarters())
+	}
+}
+
+func TestWithRequireNames(t *testing.T) {
+	loader := NewStarterLoaderWith(WithStarters(&sleeper{}, &sleeper{}))
+	if err := loader.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(loader.StartedStarters()) != "[unnamed-1 unnamed-2]" {
+		t.Fatal("unnamed starters should get unique names", loader.StartedStarters())
+	}
+	if _, err := loader.StopStarter("unnamed-2", time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if status, _ := loader.GetStatus("unnamed-1"); status != StarterStatusStarted {
+		t.Fatal("only the second unnamed starter should be stopped", status)
+	}
+	loader = NewStarterLoaderWith(WithRequireNames(true), WithStarters(&sleeper{name: "redis"}, &sleeper{}))
+	err := loader.Start()
+	if err == nil || !strings.Contains(err.Error(), "starterName is required") {
+		t.Fatal("unnamed starter should fail when names are required", err)
+	}
+	if len(loader.StartedStarters()) != 0 {
+		t.Fatal("nothing should be started")
+	}
+	loader = NewStarterLoaderWith(WithRequireNames(true), WithStarters(&sleeper{name: "redis"}))
+	if err = loader.AddStarter(&sleeper{}); err == nil {
+		t.Fatal("AddStarter should reject unnamed starter when names are required")
+	}
+	if err = loader.Start(); err != nil {
+		t.Fatal(err)
+	}
+}
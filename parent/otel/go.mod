@@ -0,0 +1,24 @@
+module github.com/golang-acexy/starter-parent/parent/otel
+
+go 1.21.0
+
+toolchain go1.21.5
+
+require (
+	github.com/golang-acexy/starter-parent v0.0.0-20261015024936-d801470df76d
+	go.opentelemetry.io/otel v1.24.0
+	go.opentelemetry.io/otel/sdk v1.24.0
+	go.opentelemetry.io/otel/trace v1.24.0
+)
+
+require (
+	github.com/acexy/golang-toolkit v0.0.38 // indirect
+	github.com/go-logr/logr v1.4.1 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/sirupsen/logrus v1.9.3 // indirect
+	github.com/timandy/routine v1.1.4 // indirect
+	go.opentelemetry.io/otel/metric v1.24.0 // indirect
+	golang.org/x/sys v0.28.0 // indirect
+	gopkg.in/natefinch/lumberjack.v2 v2.2.1 // indirect
+)
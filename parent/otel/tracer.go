@@ -0,0 +1,46 @@
+// Package otel 将OpenTelemetry适配为模块加载器的链路追踪 独立为子模块以避免核心模块依赖OpenTelemetry
+package otel
+
+import (
+	"context"
+	"github.com/golang-acexy/starter-parent/parent"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// 创建Tracer使用的instrumentation名称
+const instrumentationName = "github.com/golang-acexy/starter-parent"
+
+// tracer 基于OpenTelemetry Tracer的链路追踪
+type tracer struct {
+	tracer trace.Tracer
+}
+
+// NewTracer 使用TracerProvider创建加载器的链路追踪 span名称为starter.start/<name>、starter.stop/<name>
+func NewTracer(provider trace.TracerProvider) parent.Tracer {
+	return &tracer{tracer: provider.Tracer(instrumentationName)}
+}
+
+// WithTracerProvider 设置加载器使用的TracerProvider 等同于parent.WithTracer(NewTracer(provider))
+func WithTracerProvider(provider trace.TracerProvider) parent.LoaderOption {
+	return parent.WithTracer(NewTracer(provider))
+}
+
+func (t *tracer) Start(ctx context.Context, spanName string) (context.Context, parent.Span) {
+	ctx, span := t.tracer.Start(ctx, spanName)
+	return ctx, &otelSpan{span: span}
+}
+
+// otelSpan 记录异常时同时将span状态设置为Error
+type otelSpan struct {
+	span trace.Span
+}
+
+func (o *otelSpan) RecordError(err error) {
+	o.span.RecordError(err)
+	o.span.SetStatus(codes.Error, err.Error())
+}
+
+func (o *otelSpan) End() {
+	o.span.End()
+}
@@ -0,0 +1,44 @@
+package otel
+
+import (
+	"errors"
+	"github.com/golang-acexy/starter-parent/parent"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"testing"
+	"time"
+)
+
+func TestTracerProvider(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	loader := parent.NewStarterLoaderWith(
+		WithTracerProvider(provider),
+		parent.WithStarters(
+			parent.NewFuncStarter("gorm", nil, nil),
+			parent.NewFuncStarter("gin", nil, func(maxWaitTime time.Duration) (gracefully, stopped bool, err error) {
+				return false, false, errors.New("something error")
+			}),
+		),
+	)
+	if err := loader.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loader.Stop(time.Second); err != nil {
+		t.Fatal(err)
+	}
+	statuses := make(map[string]codes.Code)
+	for _, span := range recorder.Ended() {
+		statuses[span.Name()] = span.Status().Code
+	}
+	if len(statuses) != 4 {
+		t.Fatal("every start and stop should end a span", statuses)
+	}
+	if statuses["starter.start/gorm"] != codes.Unset || statuses["starter.stop/gorm"] != codes.Unset {
+		t.Fatal("successful lifecycle should not set error status", statuses)
+	}
+	if statuses["starter.stop/gin"] != codes.Error {
+		t.Fatal("failed stop should set error status", statuses)
+	}
+}
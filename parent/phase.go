@@ -0,0 +1,72 @@
+package parent
+
+import (
+	"context"
+	"errors"
+	"github.com/acexy/golang-toolkit/util/coll"
+)
+
+// 按阶段对模块分组 阶段的先后顺序为WithPhases设置的顺序，未设置的阶段按该阶段首个模块的注册顺序排在其后
+func groupByPhase(order []string, wrappers []*starterWrapper) [][]*starterWrapper {
+	index := make(map[string]int)
+	phases := make([][]*starterWrapper, 0)
+	for _, phase := range order {
+		if _, ok := index[phase]; !ok {
+			index[phase] = len(phases)
+			phases = append(phases, make([]*starterWrapper, 0))
+		}
+	}
+	for _, wrapper := range wrappers {
+		phase := wrapper.setting().phase
+		i, ok := index[phase]
+		if !ok {
+			i = len(phases)
+			index[phase] = i
+			phases = append(phases, make([]*starterWrapper, 0))
+		}
+		phases[i] = append(phases[i], wrapper)
+	}
+	return coll.SliceFilter(phases, func(item []*starterWrapper) bool {
+		return len(item) > 0
+	})
+}
+
+// StartByPhase 按阶段启动所有未启动的模块 前一阶段的模块全部启动后才开始启动下一阶段
+// 阶段的先后顺序参见WithPhases，阶段内按startPriority依次启动
+func (s *StarterLoader) StartByPhase() error {
+	defer s.Mutex.Unlock()
+	s.Mutex.Lock()
+	if s.starters.isEmpty() {
+		return errors.New("miss starters")
+	}
+	if err := s.checkSettings(s.starters); err != nil {
+		return err
+	}
+	for _, phase := range groupByPhase(s.phases, *s.starters) {
+		for _, wrapper := range sortByStartPriority(phase) {
+			if err := s.start(wrapper); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// StopByPhase 按阶段的相反顺序停止所有模块 后一阶段的模块全部停止后才开始停止前一阶段
+// 阶段内按stopPriority依次停止，等待时间使用模块设置的stopMaxWaitTime
+func (s *StarterLoader) StopByPhase() ([]*StopResult, error) {
+	defer s.Mutex.Unlock()
+	s.Mutex.Lock()
+	s.beginShutdown()
+	if s.starters.isEmpty() {
+		return nil, errors.New("no starter")
+	}
+	phases := groupByPhase(s.phases, *s.starters)
+	stopResult := make([]*StopResult, 0)
+	for i := len(phases) - 1; i >= 0; i-- {
+		for _, wrapper := range sortByStopPriority(phases[i]) {
+			stopResult = append(stopResult, s.stop(context.Background(), wrapper, wrapper.setting().stopMaxWaitTime))
+		}
+	}
+	return stopResult, nil
+}
@@ -0,0 +1,47 @@
+package parent
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// phased 设置启动阶段的测试模块
+type phased struct {
+	recorder
+	phase         string
+	startPriority uint
+	stopPriority  uint
+}
+
+func (p phased) Setting() *Setting {
+	return NewSetting(p.name, p.stopPriority, false, time.Second, nil).WithPhase(p.phase).WithStartPriority(p.startPriority)
+}
+
+func TestStartAndStopByPhase(t *testing.T) {
+	var record []string
+	module := func(name, phase string, startPriority, stopPriority uint) *phased {
+		return &phased{recorder: recorder{name: name, record: &record}, phase: phase, startPriority: startPriority, stopPriority: stopPriority}
+	}
+	loader := NewStarterLoaderWith(WithPhases("infra", "core", "web"), WithStarters(
+		module("gorm", "infra", 2, 1),
+		module("gin", "web", 1, 0),
+		module("redis", "infra", 1, 0),
+		module("service", "core", 0, 0),
+		module("grpc", "web", 0, 1),
+	))
+	if err := loader.StartByPhase(); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(record) != "[redis gorm service grpc gin]" {
+		t.Fatal("unexpected start order", record)
+	}
+	record = nil
+	result, err := loader.StopByPhase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(record) != "[gin grpc service redis gorm]" || len(result) != 5 {
+		t.Fatal("unexpected stop order", record)
+	}
+}
@@ -0,0 +1,82 @@
+package parent
+
+import (
+	"errors"
+	"github.com/acexy/golang-toolkit/util/coll"
+)
+
+// PlanStart 按执行顺序返回Start将要启动的模块名 不执行启动
+// 顺序包含依赖关系的排序，已启动、正在启动/停止及不启用的模块不包含在内
+func (s *StarterLoader) PlanStart() ([]string, error) {
+	starters := s.snapshot()
+	if starters.isEmpty() {
+		return nil, errors.New("miss starters")
+	}
+	ordered, err := dependencyOrder(*starters)
+	if err != nil {
+		return nil, err
+	}
+	return startPlan(ordered), nil
+}
+
+// PlanStartBySetting 按执行顺序返回StartBySetting将要启动的模块名 不执行启动
+func (s *StarterLoader) PlanStartBySetting() ([]string, error) {
+	starters := s.snapshot()
+	if starters.isEmpty() {
+		return nil, errors.New("miss starters")
+	}
+	ordered, err := dependencyOrder(sortByStartPriority(*starters))
+	if err != nil {
+		return nil, err
+	}
+	return startPlan(ordered), nil
+}
+
+// PlanStop 按执行顺序返回Stop将要停止的已启动模块名 不执行停止
+func (s *StarterLoader) PlanStop() ([]string, error) {
+	starters := s.snapshot()
+	if starters.isEmpty() {
+		return nil, errors.New("no starter")
+	}
+	return stopPlan(*starters), nil
+}
+
+// PlanStopBySetting 按梯队返回StopBySetting将要停止的已启动模块名 不执行停止
+// 梯队按停止顺序排列，包含stopPriority及依赖关系的排序，同一梯队内的模块将并发停止
+func (s *StarterLoader) PlanStopBySetting() ([][]string, error) {
+	starters := s.snapshot()
+	if starters.isEmpty() {
+		return nil, errors.New("no starter")
+	}
+	sorted, priorities := s.stopOrder(*starters)
+	tiers := make([][]string, 0)
+	for begin := 0; begin < len(sorted); {
+		end := begin
+		for end < len(sorted) && priorities[end] == priorities[begin] {
+			end++
+		}
+		if tier := stopPlan(sorted[begin:end]); len(tier) > 0 {
+			tiers = append(tiers, tier)
+		}
+		begin = end
+	}
+	return tiers, nil
+}
+
+// 按顺序获取将被启动的模块名
+func startPlan(ordered []*starterWrapper) []string {
+	return coll.SliceCollect(coll.SliceFilter(ordered, func(item *starterWrapper) bool {
+		return item.startable() && !item.disabled()
+	}), func(item *starterWrapper) string {
+		return item.getStarterName()
+	})
+}
+
+// 按顺序获取将被停止的模块名
+func stopPlan(ordered []*starterWrapper) []string {
+	return coll.SliceCollect(coll.SliceFilter(ordered, func(item *starterWrapper) bool {
+		return item.getStatus() == StarterStatusStarted
+	}), func(item *starterWrapper) string {
+		return item.getStarterName()
+	})
+}
@@ -0,0 +1,92 @@
+package parent
+
+import (
+	"fmt"
+	"github.com/acexy/golang-toolkit/util/coll"
+	"testing"
+)
+
+func TestPlan(t *testing.T) {
+	loader := NewStarterLoader([]Starter{&redis{}, &gorm{}, &gin{}})
+	if plan, _ := loader.PlanStop(); len(plan) != 0 {
+		t.Fatal("nothing should be stopped before start", plan)
+	}
+	planned, err := loader.PlanStart()
+	if err != nil {
+		t.Fatal(err)
+	}
+	started, err := loader.StartDetailed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(planned) != fmt.Sprint(coll.SliceCollect(started, func(item *StartResult) string { return item.StarterName })) {
+		t.Fatal("start plan should match start order", planned)
+	}
+	if plan, _ := loader.PlanStart(); len(plan) != 0 {
+		t.Fatal("nothing should be started twice", plan)
+	}
+	tiers, err := loader.PlanStopBySetting()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stopped, err := loader.StopBySetting()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(tiers) != "[[gin] [unnamed-1] [gorm]]" || fmt.Sprint(flatten(tiers)) != fmt.Sprint(coll.SliceCollect(stopped, func(item *StopResult) string { return item.StarterName })) {
+		t.Fatal("stop plan should match stop order", tiers)
+	}
+}
+
+func TestPlanWithDependency(t *testing.T) {
+	var record []string
+	loader := NewStarterLoader([]Starter{
+		&stopDependent{recorder: recorder{name: "gin", record: &record}, dependsOn: []string{"gorm"}, priority: 5},
+		&stopDependent{recorder: recorder{name: "gorm", record: &record}, priority: 0},
+		&stopDependent{recorder: recorder{name: "redis", record: &record}, priority: 1},
+	})
+	planned, _ := loader.PlanStart()
+	if err := loader.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(planned) != "[gorm gin redis]" || fmt.Sprint(planned) != fmt.Sprint(record) {
+		t.Fatal("start plan should follow dependency order", planned, record)
+	}
+	record = nil
+	tiers, _ := loader.PlanStopBySetting()
+	if _, err := loader.StopBySetting(); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(tiers) != "[[redis] [gin] [gorm]]" || fmt.Sprint(flatten(tiers)) != fmt.Sprint(record) {
+		t.Fatal("stop plan should follow dependency order", tiers, record)
+	}
+}
+
+func TestPlanStopTiers(t *testing.T) {
+	loader := NewStarterLoader([]Starter{
+		NewFuncStarter("grpc", nil, nil, WithFuncStop(0, false, 0)),
+		NewFuncStarter("redis", nil, nil, WithFuncStop(19, true, 0)),
+		NewFuncStarter("gin", nil, nil, WithFuncStop(0, false, 0)),
+		NewFuncStarter("cron", nil, nil, WithFuncStop(10, false, 0)),
+		NewFuncStarter("mongo", nil, nil, WithFuncStop(21, false, 0)),
+	})
+	if err := loader.StartStarters("grpc", "redis", "gin", "mongo"); err != nil {
+		t.Fatal(err)
+	}
+	tiers, err := loader.PlanStopBySetting()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(tiers) != "[[grpc gin] [redis] [mongo]]" {
+		t.Fatal("modules sharing a stopPriority should be planned in one tier, not started modules omitted", tiers)
+	}
+}
+
+// 将梯队展开为停止顺序
+func flatten(tiers [][]string) []string {
+	flattened := make([]string, 0)
+	for _, tier := range tiers {
+		flattened = append(flattened, tier...)
+	}
+	return flattened
+}
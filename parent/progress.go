@@ -0,0 +1,41 @@
+package parent
+
+import "time"
+
+// ProgressReporter 可选实现的停止接口 停止时由loader传入汇报停止进度的回调，适用于需要逐步排空连接等耗时较长的模块
+// 当模块实现了ContextStarter时loader优先调用StopWithContext，否则优先调用StopWithProgress
+//
+//	report 汇报停止进度 percent为完成的百分比(0-100)，remaining为剩余待处理的数量(如连接数)
+type ProgressReporter interface {
+	StopWithProgress(maxWaitTime time.Duration, report func(percent float64, remaining int)) (gracefully, stopped bool, err error)
+}
+
+// StopProgress 模块停止过程中汇报的进度
+type StopProgress struct {
+	// 停止中的模块
+	StarterName string
+	// 完成的百分比 (0-100)
+	Percent float64
+	// 剩余待处理的数量
+	Remaining int
+	// 汇报时间
+	Timestamp time.Time
+}
+
+// 不处理进度的默认回调
+func discardProgress(float64, int) {}
+
+// 获取指定模块汇报停止进度的回调 未设置进度接收方时不做任何处理
+func (s *StarterLoader) progressReporter(starterName string) func(percent float64, remaining int) {
+	if s.progressSink == nil {
+		return discardProgress
+	}
+	return func(percent float64, remaining int) {
+		s.progressSink(StopProgress{
+			StarterName: starterName,
+			Percent:     percent,
+			Remaining:   remaining,
+			Timestamp:   time.Now(),
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package parent
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// draining 停止时汇报排空进度的测试模块
+type draining struct {
+}
+
+func (d draining) Setting() *Setting {
+	return NewSetting("draining", 0, false, time.Second, nil)
+}
+
+func (d draining) Start() (interface{}, error) {
+	return &d, nil
+}
+
+func (d draining) Stop(maxWaitTime time.Duration) (gracefully bool, stopped bool, err error) {
+	return d.StopWithProgress(maxWaitTime, func(float64, int) {})
+}
+
+func (d draining) StopWithProgress(maxWaitTime time.Duration, report func(percent float64, remaining int)) (gracefully bool, stopped bool, err error) {
+	report(50, 10)
+	report(100, 0)
+	return true, true, nil
+}
+
+func TestStopProgress(t *testing.T) {
+	progress := make([]string, 0)
+	loader := NewStarterLoaderWith(WithStopProgressSink(func(p StopProgress) {
+		progress = append(progress, fmt.Sprintf("%s:%v:%d", p.StarterName, p.Percent, p.Remaining))
+	}), WithStarters(&draining{}, &sleeper{name: "quiet"}))
+	if err := loader.Start(); err != nil {
+		t.Fatal(err)
+	}
+	result, err := loader.StopBySetting()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result[0].Stopped || !result[1].Stopped {
+		t.Fatal("all modules should be stopped", result)
+	}
+	if fmt.Sprint(progress) != "[draining:50:10 draining:100:0]" {
+		t.Fatal("progress should be forwarded to the sink", progress)
+	}
+	loader = NewStarterLoader([]Starter{&draining{}})
+	if err = loader.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if result, _ := loader.StopStarter("draining", time.Second); !result.Stopped {
+		t.Fatal("progress without sink should be discarded", result)
+	}
+}
@@ -0,0 +1,97 @@
+package parent
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// RunUntilSignal 启动所有模块并阻塞至收到指定的系统信号，随后按照卸载配置停止所有模块
+// 		signals 等待的系统信号 默认为SIGINT及SIGTERM
+// 启动失败时不再等待信号，直接停止所有已启动的模块
+func (s *StarterLoader) RunUntilSignal(signals ...os.Signal) []*StopResult {
+	if len(signals) == 0 {
+		signals = []os.Signal{syscall.SIGINT, syscall.SIGTERM}
+	}
+	if err := s.Start(); err != nil {
+		s.log().WithError(err).Errorln("start failed, stopping started starters")
+	} else {
+		ch := s.signals
+		if ch == nil {
+			ch = make(chan os.Signal, 1)
+			signal.Notify(ch, signals...)
+			defer signal.Stop(ch)
+		}
+		sig := <-ch
+		s.log().Traceln("received signal", sig, "stopping now...")
+	}
+	result, err := s.StopBySetting()
+	if err != nil {
+		s.log().WithError(err).Errorln("stop failed with error:", err)
+	}
+	return result
+}
+
+// Shutdown 按照卸载配置停止所有模块 并将结果合并为一个异常，适用于在main中据此决定退出码
+// 所有模块均优雅停机(或因未启动而跳过)时返回nil，否则返回以模块名为前缀的各模块异常的合并
+// 		allMaxWaitTime 全局等待时间 与StopBySetting一致，需要详细的停止结果时可直接使用StopBySetting
+func (s *StarterLoader) Shutdown(allMaxWaitTime ...time.Duration) error {
+	result, err := s.StopBySetting(allMaxWaitTime...)
+	errs := []error{err}
+	for _, r := range result {
+		switch {
+		case r == nil || r.Skipped || (r.Gracefully && r.Stopped && r.Error == nil):
+		case r.Error != nil:
+			errs = append(errs, r.Error)
+		default:
+			errs = append(errs, errors.New(r.StarterName+": not stopped gracefully"))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ShutdownContext 获取在开始停止所有模块时被取消的context 模块启动的后台任务可据此主动结束
+// 执行Stop、StopReverse、StopBySetting、StopBySettingStream、StopByPhase(及基于它们的Shutdown等)时，在开始停止模块前取消，停止单个模块时不取消
+// 开始停止后获取的context已被取消，停止完成后再次启动模块或Reset时将重新创建，因此新启动的模块获取的context不会处于取消状态
+func (s *StarterLoader) ShutdownContext() context.Context {
+	defer s.shutdownMutex.Unlock()
+	s.shutdownMutex.Lock()
+	if s.shutdownCtx == nil {
+		s.shutdownCtx, s.shutdownCancel = context.WithCancel(context.Background())
+		if s.shutdownBegun {
+			s.shutdownCancel()
+		}
+	}
+	return s.shutdownCtx
+}
+
+// 标记开始停止所有模块 并取消ShutdownContext
+func (s *StarterLoader) beginShutdown() {
+	defer s.shutdownMutex.Unlock()
+	s.shutdownMutex.Lock()
+	s.shutdownBegun = true
+	if s.shutdownCancel != nil {
+		s.shutdownCancel()
+	}
+}
+
+// 是否已开始停止所有模块 且停止后尚未再次启动模块
+func (s *StarterLoader) shutdownStarted() bool {
+	defer s.shutdownMutex.Unlock()
+	s.shutdownMutex.Lock()
+	return s.shutdownBegun
+}
+
+// 已开始停止时清除ShutdownContext 下次获取时重新创建
+func (s *StarterLoader) resetShutdown() {
+	defer s.shutdownMutex.Unlock()
+	s.shutdownMutex.Lock()
+	if !s.shutdownBegun {
+		return
+	}
+	s.shutdownBegun = false
+	s.shutdownCtx, s.shutdownCancel = nil, nil
+}
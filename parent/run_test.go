@@ -0,0 +1,86 @@
+package parent
+
+import (
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestRunUntilSignal(t *testing.T) {
+	loader := NewStarterLoader([]Starter{&sleeper{name: "first"}, &sleeper{name: "second"}})
+	loader.signals = make(chan os.Signal, 1)
+	done := make(chan []*StopResult)
+	go func() {
+		done <- loader.RunUntilSignal()
+	}()
+	time.Sleep(time.Millisecond * 50)
+	loader.signals <- syscall.SIGTERM
+	select {
+	case result := <-done:
+		if len(result) != 2 || !result[0].Stopped || !result[1].Stopped {
+			t.Fatal("all starters should be stopped", result)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("RunUntilSignal did not return after signal")
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	loader := NewStarterLoader([]Starter{&redis{}, &gorm{}, &gin{}})
+	if err := loader.Start(); err != nil {
+		t.Fatal(err)
+	}
+	err := loader.Shutdown()
+	if err == nil || !strings.Contains(err.Error(), "gin: something error") {
+		t.Fatal("shutdown error should contain the gin failure", err)
+	}
+	if strings.Contains(err.Error(), "gorm") || strings.Contains(err.Error(), "unnamed") {
+		t.Fatal("gracefully stopped modules should not be reported", err)
+	}
+	loader = NewStarterLoader([]Starter{&sleeper{name: "first"}, &sleeper{name: "second"}})
+	if err = loader.StartStarter("first"); err != nil {
+		t.Fatal(err)
+	}
+	if err = loader.Shutdown(); err != nil {
+		t.Fatal("graceful shutdown should return nil", err)
+	}
+}
+
+func TestShutdownContext(t *testing.T) {
+	loader := NewStarterLoader([]Starter{&sleeper{name: "first"}})
+	ctx := loader.ShutdownContext()
+	if err := loader.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if ctx.Err() != nil {
+		t.Fatal("shutdown context should not be canceled before stop")
+	}
+	if _, err := loader.StopBySetting(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-ctx.Done():
+	default:
+		t.Fatal("shutdown context should be canceled by StopBySetting")
+	}
+	if loader.ShutdownContext().Err() == nil {
+		t.Fatal("shutdown context obtained after stop should be canceled")
+	}
+	if err := loader.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if loader.ShutdownContext().Err() != nil {
+		t.Fatal("shutdown context should be recreated when starting again after stop")
+	}
+	if _, err := loader.Stop(time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if err := loader.Reset(); err != nil {
+		t.Fatal(err)
+	}
+	if loader.ShutdownContext().Err() != nil {
+		t.Fatal("shutdown context should be recreated after reset")
+	}
+}
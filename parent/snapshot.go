@@ -0,0 +1,42 @@
+package parent
+
+// LoaderSnapshot 加载器中所有模块状态的快照 可直接序列化为JSON，适用于管理界面及调试输出
+type LoaderSnapshot struct {
+	Starters []StarterSnapshot `json:"starters"`
+}
+
+// StarterSnapshot 单个模块状态的快照
+type StarterSnapshot struct {
+	// 模块名称
+	Name string `json:"name"`
+	// 模块状态
+	Status string `json:"status"`
+	// 是否已启动
+	Started bool `json:"started"`
+	// 最近一次启动/停止的异常信息 成功时为空
+	Error string `json:"error,omitempty"`
+}
+
+// Snapshot 获取所有模块状态的快照 按注册顺序排列
+// 快照在生命周期操作锁内生成以保证一致，正在进行启动/停止时将阻塞直到其完成
+func (s *StarterLoader) Snapshot() LoaderSnapshot {
+	defer s.Mutex.Unlock()
+	s.Mutex.Lock()
+	snapshot := LoaderSnapshot{Starters: make([]StarterSnapshot, 0)}
+	if s.starters.isEmpty() {
+		return snapshot
+	}
+	for _, wrapper := range *s.starters {
+		status := wrapper.getStatus()
+		starter := StarterSnapshot{
+			Name:    wrapper.getStarterName(),
+			Status:  status.String(),
+			Started: status == StarterStatusStarted,
+		}
+		if err := wrapper.getLastError(); err != nil {
+			starter.Error = err.Error()
+		}
+		snapshot.Starters = append(snapshot.Starters, starter)
+	}
+	return snapshot
+}
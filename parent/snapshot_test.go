@@ -0,0 +1,27 @@
+package parent
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSnapshot(t *testing.T) {
+	loader := NewStarterLoader([]Starter{&sleeper{name: "gorm"}, &failing{name: "broken"}, &sleeper{name: "gin"}})
+	if err := loader.StartStarter("gorm"); err != nil {
+		t.Fatal(err)
+	}
+	if err := loader.StartStarter("broken"); err == nil {
+		t.Fatal("broken should fail to start")
+	}
+	marshaled, err := json.Marshal(loader.Snapshot())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"starters":[` +
+		`{"name":"gorm","status":"started","started":true},` +
+		`{"name":"broken","status":"not started","started":false,"error":"broken start failed"},` +
+		`{"name":"gin","status":"not started","started":false}]}`
+	if string(marshaled) != expected {
+		t.Fatal("unexpected snapshot", string(marshaled))
+	}
+}
@@ -0,0 +1,78 @@
+package parent
+
+import "errors"
+
+// 汇总中最多记录的失败模块名称数量
+const summaryFailedNamesLimit = 5
+
+// StopSummary 模块停止结果汇总
+type StopSummary struct {
+	// 模块总数
+	Total int
+	// 优雅停机的模块数
+	Gracefully int
+	// 停止失败的模块数 (异常或未能完成停止)
+	Failed int
+	// 因未启动而跳过的模块数
+	Skipped int
+	// 前若干个停止失败的模块名称
+	FailedStarters []string
+	// 所有停止异常的合并
+	Error error
+}
+
+// SummarizeStopResults 汇总模块停止结果
+func SummarizeStopResults(results []*StopResult) StopSummary {
+	summary := StopSummary{FailedStarters: make([]string, 0)}
+	errs := make([]error, 0)
+	for _, result := range results {
+		if result == nil {
+			continue
+		}
+		summary.Total++
+		switch {
+		case result.Skipped:
+			summary.Skipped++
+		case result.failed():
+			summary.Failed++
+			if len(summary.FailedStarters) < summaryFailedNamesLimit {
+				summary.FailedStarters = append(summary.FailedStarters, result.StarterName)
+			}
+			if result.Error != nil {
+				errs = append(errs, result.Error)
+			}
+		}
+		if result.Gracefully {
+			summary.Gracefully++
+		}
+	}
+	summary.Error = errors.Join(errs...)
+	return summary
+}
+
+// NonGracefulStops 未能优雅停机的模块名 因未启动而跳过的模块不包含在内
+func NonGracefulStops(results []*StopResult) []string {
+	starterNames := make([]string, 0)
+	for _, result := range results {
+		if result != nil && !result.Skipped && !result.Gracefully {
+			starterNames = append(starterNames, result.StarterName)
+		}
+	}
+	return starterNames
+}
+
+// FailedStops 停止失败的模块名 (异常或未能完成停止) 因未启动而跳过的模块不包含在内
+func FailedStops(results []*StopResult) []string {
+	starterNames := make([]string, 0)
+	for _, result := range results {
+		if result != nil && !result.Skipped && result.failed() {
+			starterNames = append(starterNames, result.StarterName)
+		}
+	}
+	return starterNames
+}
+
+// 模块是否停止失败 (异常或未能完成停止)
+func (r *StopResult) failed() bool {
+	return r.Error != nil || !r.Stopped
+}
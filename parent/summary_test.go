@@ -0,0 +1,47 @@
+package parent
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestSummarizeStopResults(t *testing.T) {
+	loader := NewStarterLoader(starters)
+	if err := loader.Start(); err != nil {
+		t.Fatal(err)
+	}
+	result, err := loader.Stop(time.Second * 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	summary := SummarizeStopResults(append(result, &StopResult{StarterName: "never", Skipped: true}))
+	fmt.Printf("%+v\n", summary)
+	if summary.Total != 4 || summary.Failed != 1 || summary.Skipped != 1 || summary.Gracefully != 2 {
+		t.Fatalf("unexpected summary %+v", summary)
+	}
+	if fmt.Sprint(summary.FailedStarters) != "[gin]" || summary.Error == nil {
+		t.Fatalf("gin failure should be reported %+v", summary)
+	}
+}
+
+func TestNonGracefulAndFailedStops(t *testing.T) {
+	abrupt := NewFuncStarter("abrupt", nil, func(maxWaitTime time.Duration) (gracefully, stopped bool, err error) {
+		return false, true, nil
+	})
+	loader := NewStarterLoader([]Starter{&redis{}, &gorm{}, &gin{}, abrupt})
+	if err := loader.Start(); err != nil {
+		t.Fatal(err)
+	}
+	result, err := loader.Stop(time.Second * 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result = append(result, &StopResult{StarterName: "never", Skipped: true})
+	if fmt.Sprint(NonGracefulStops(result)) != "[gin abrupt]" {
+		t.Fatal("unexpected non graceful stops", NonGracefulStops(result))
+	}
+	if fmt.Sprint(FailedStops(result)) != "[gin]" {
+		t.Fatal("unexpected failed stops", FailedStops(result))
+	}
+}
@@ -0,0 +1,138 @@
+package parent
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// RestartPolicy 模块被监管时的重启策略
+type RestartPolicy int
+
+const (
+	// RestartPolicyNever 不重启
+	RestartPolicyNever RestartPolicy = iota
+	// RestartPolicyOnFailure 已启动的模块健康检查失败时重启
+	RestartPolicyOnFailure
+	// RestartPolicyAlways 健康检查失败时重启 模块未运行(未启动或已停止)时也将启动该模块
+	RestartPolicyAlways
+)
+
+// 连续重启失败时退避时间相对监管间隔的最大倍数 (2的幂)
+const maxSuperviseBackoffShift = 5
+
+// 单个模块的监管状态
+type supervision struct {
+	// 连续重启失败的次数
+	failures int
+	// 下一次允许重启的时间
+	next time.Time
+}
+
+// Supervise 监管已启动的模块 每隔interval对设置了重启策略的模块执行健康检查，并按策略重启
+// 重启失败时按interval的倍数指数退避，最长为interval的32倍；该方法将阻塞直到ctx结束并返回ctx.Err()
+// 开始停止所有模块(ShutdownContext被取消)时结束监管并返回ShutdownContext的异常，已停止的模块不会被重新启动
+// 未实现HealthChecker且未设置健康检查的模块仅在RestartPolicyAlways策略下于未运行时被启动
+func (s *StarterLoader) Supervise(ctx context.Context, interval time.Duration) error {
+	if interval <= 0 {
+		return errors.New("supervise interval must be positive")
+	}
+	shutdown := s.ShutdownContext()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	states := make(map[*starterWrapper]*supervision)
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-shutdown.Done():
+			return shutdown.Err()
+		case <-ticker.C:
+			s.supervise(ctx, shutdown, interval, states)
+		}
+	}
+}
+
+// 执行一轮监管 开始停止所有模块后不再重启模块
+func (s *StarterLoader) supervise(ctx, shutdown context.Context, interval time.Duration, states map[*starterWrapper]*supervision) {
+	starters := s.snapshot()
+	if starters.isEmpty() {
+		return
+	}
+	for _, wrapper := range *starters {
+		if ctx.Err() != nil || shutdown.Err() != nil {
+			return
+		}
+		setting := wrapper.starter.Setting()
+		if setting == nil || setting.restartPolicy == RestartPolicyNever {
+			continue
+		}
+		state, ok := states[wrapper]
+		if !ok {
+			state = &supervision{}
+			states[wrapper] = state
+		}
+		if time.Now().Before(state.next) {
+			continue
+		}
+		starterName := wrapper.getStarterName()
+		var err error
+		switch wrapper.getStatus() {
+		case StarterStatusStarted:
+			checker, ok := wrapper.healthChecker()
+			if !ok {
+				continue
+			}
+			checkCtx, cancel := context.WithTimeout(ctx, interval)
+			unhealthy := check(checkCtx, starterName, checker)
+			cancel()
+			if unhealthy == nil || ctx.Err() != nil || shutdown.Err() != nil {
+				continue
+			}
+			s.log().WithError(unhealthy).Warnln(starterName, "health check failed, restart now")
+			var restarted bool
+			restarted, err = s.superviseRestart(func() error {
+				_, err := s.restartStarter(starterName, setting.stopMaxWaitTime)
+				return err
+			})
+			if !restarted {
+				return
+			}
+		case StarterStatusNotStarted, StarterStatusStopped:
+			if setting.restartPolicy != RestartPolicyAlways || wrapper.disabled() || shutdown.Err() != nil {
+				continue
+			}
+			s.log().Warnln(starterName, "is not running, start now")
+			var started bool
+			started, err = s.superviseRestart(func() error {
+				return s.startStarter(starterName)
+			})
+			if !started {
+				return
+			}
+		default:
+			// 启动中或停止中的模块等待下一轮监管
+			continue
+		}
+		if err != nil {
+			state.failures++
+			backoff := interval << min(state.failures, maxSuperviseBackoffShift)
+			state.next = time.Now().Add(backoff)
+			s.log().WithError(err).Errorln(starterName, "restart failed, retry after", backoff)
+			continue
+		}
+		state.failures = 0
+		state.next = time.Time{}
+	}
+}
+
+// 持有锁执行监管的重启/启动 已开始停止所有模块时不执行并返回false
+// 在锁内检查可避免与停止并发时，在停止完成后重新启动已停止的模块
+func (s *StarterLoader) superviseRestart(restart func() error) (bool, error) {
+	defer s.Mutex.Unlock()
+	s.Mutex.Lock()
+	if s.shutdownStarted() {
+		return false, nil
+	}
+	return true, restart()
+}
@@ -0,0 +1,148 @@
+package parent
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// supervised 健康检查失败指定次数的测试模块
+type supervised struct {
+	policy   RestartPolicy
+	failures atomic.Int32
+	starts   atomic.Int32
+	stops    atomic.Int32
+}
+
+func (s *supervised) Setting() *Setting {
+	return NewSetting("supervised", 0, false, time.Second, nil).WithRestartPolicy(s.policy)
+}
+
+func (s *supervised) Start() (interface{}, error) {
+	s.starts.Add(1)
+	return s, nil
+}
+
+func (s *supervised) Stop(maxWaitTime time.Duration) (gracefully bool, stopped bool, err error) {
+	s.stops.Add(1)
+	return true, true, nil
+}
+
+func (s *supervised) HealthCheck(ctx context.Context) error {
+	if s.failures.Add(-1) >= 0 {
+		return errors.New("unhealthy")
+	}
+	return nil
+}
+
+// 等待条件满足 超时返回false
+func eventually(timeout time.Duration, condition func() bool) bool {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if condition() {
+			return true
+		}
+		time.Sleep(time.Millisecond * 5)
+	}
+	return condition()
+}
+
+func TestSuperviseOnFailure(t *testing.T) {
+	module := &supervised{policy: RestartPolicyOnFailure}
+	module.failures.Store(1)
+	loader := NewStarterLoader([]Starter{module})
+	if err := loader.Start(); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- loader.Supervise(ctx, time.Millisecond*10)
+	}()
+	if !eventually(time.Second, func() bool { return module.starts.Load() == 2 }) {
+		t.Fatal("unhealthy module should be restarted", module.starts.Load())
+	}
+	time.Sleep(time.Millisecond * 50)
+	if module.starts.Load() != 2 || module.stops.Load() != 1 {
+		t.Fatal("healthy module should not be restarted again", module.starts.Load(), module.stops.Load())
+	}
+	cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatal("unexpected supervise result", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("supervise should stop when ctx is canceled")
+	}
+}
+
+func TestSupervisePolicies(t *testing.T) {
+	never := &supervised{policy: RestartPolicyNever}
+	never.failures.Store(100)
+	loader := NewStarterLoader([]Starter{never})
+	_ = loader.Start()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+	_ = loader.Supervise(ctx, time.Millisecond*10)
+	if never.starts.Load() != 1 {
+		t.Fatal("module with never policy should not be restarted", never.starts.Load())
+	}
+
+	always := &supervised{policy: RestartPolicyAlways}
+	loader = NewStarterLoader([]Starter{always})
+	ctx, cancel = context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+	_ = loader.Supervise(ctx, time.Millisecond*10)
+	if always.starts.Load() != 1 || !loader.IsAllStarted() {
+		t.Fatal("module with always policy should be started when not running", always.starts.Load())
+	}
+}
+
+func TestSuperviseStopsOnShutdown(t *testing.T) {
+	module := &supervised{policy: RestartPolicyAlways}
+	loader := NewStarterLoader([]Starter{module})
+	if err := loader.Start(); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- loader.Supervise(context.Background(), time.Millisecond*10)
+	}()
+	if _, err := loader.StopBySetting(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatal("unexpected supervise result", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("supervise should end once shutdown begins")
+	}
+	time.Sleep(time.Millisecond * 50)
+	if status, _ := loader.GetStatus("supervised"); status != StarterStatusStopped || module.starts.Load() != 1 {
+		t.Fatal("module stopped by StopBySetting should stay stopped", status, module.starts.Load())
+	}
+}
+
+func TestSuperviseRestartAfterShutdownBegins(t *testing.T) {
+	module := &supervised{policy: RestartPolicyAlways}
+	loader := NewStarterLoader([]Starter{module})
+	if err := loader.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loader.StopBySetting(); err != nil {
+		t.Fatal(err)
+	}
+	// 模拟监管在停止前已完成检查 随后才获取到锁
+	loader.supervise(context.Background(), context.Background(), time.Millisecond*10, make(map[*starterWrapper]*supervision))
+	if status, _ := loader.GetStatus("supervised"); status != StarterStatusStopped || module.starts.Load() != 1 {
+		t.Fatal("module stopped by shutdown should not be restarted", status, module.starts.Load())
+	}
+	if loader.ShutdownContext().Err() == nil {
+		t.Fatal("shutdown context should stay canceled")
+	}
+}
@@ -0,0 +1,72 @@
+package parent
+
+import "context"
+
+// Tracer 模块启动/停止使用的链路追踪 包本身不依赖具体的追踪库，OpenTelemetry可使用子模块parent/otel接入
+type Tracer interface {
+	// Start 创建一个span 返回的context携带该span，停止时将传递给ContextStarter
+	Start(ctx context.Context, spanName string) (context.Context, Span)
+}
+
+// Span 一次启动/停止对应的追踪片段
+type Span interface {
+	// RecordError 记录启动/停止异常
+	RecordError(err error)
+	// End 结束span
+	End()
+}
+
+// 不追踪的默认实现
+type noopTracer struct{}
+
+type noopSpan struct{}
+
+func (noopTracer) Start(ctx context.Context, _ string) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+func (noopSpan) RecordError(error) {}
+
+func (noopSpan) End() {}
+
+// 获取加载器使用的链路追踪
+func (s *StarterLoader) tracer() Tracer {
+	if s.lifecycleTracer != nil {
+		return s.lifecycleTracer
+	}
+	return noopTracer{}
+}
+
+// 创建模块启动/停止的span 链路追踪panic时不影响启动/停止流程
+func (s *StarterLoader) startSpan(ctx context.Context, starterName, spanName string) (context.Context, Span) {
+	spanCtx, span := ctx, Span(noopSpan{})
+	s.invokeCallback(starterName, "tracer", func() {
+		spanCtx, span = s.tracer().Start(ctx, spanName)
+	})
+	if spanCtx == nil {
+		spanCtx = ctx
+	}
+	if span == nil {
+		span = noopSpan{}
+	}
+	return spanCtx, guardedSpan{loader: s, starterName: starterName, span: span}
+}
+
+// 将span的panic转换为异常日志
+type guardedSpan struct {
+	loader      *StarterLoader
+	starterName string
+	span        Span
+}
+
+func (g guardedSpan) RecordError(err error) {
+	g.loader.invokeCallback(g.starterName, "span", func() {
+		g.span.RecordError(err)
+	})
+}
+
+func (g guardedSpan) End() {
+	g.loader.invokeCallback(g.starterName, "span", func() {
+		g.span.End()
+	})
+}
@@ -0,0 +1,85 @@
+package parent
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeSpan 记录异常及是否结束的span
+type fakeSpan struct {
+	name  string
+	err   error
+	ended bool
+}
+
+func (f *fakeSpan) RecordError(err error) {
+	f.err = err
+}
+
+func (f *fakeSpan) End() {
+	f.ended = true
+}
+
+// fakeTracer 记录创建的span
+type fakeTracer struct {
+	mu    sync.Mutex
+	spans []*fakeSpan
+}
+
+type spanKey struct{}
+
+func (f *fakeTracer) Start(ctx context.Context, spanName string) (context.Context, Span) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	span := &fakeSpan{name: spanName}
+	f.spans = append(f.spans, span)
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// traced 停止时检查context是否携带span的测试模块
+type traced struct {
+	sleeper
+	traced bool
+}
+
+func (t *traced) Setting() *Setting {
+	return NewSetting(t.name, 0, false, time.Second, nil)
+}
+
+func (t *traced) Start() (interface{}, error) {
+	return t, nil
+}
+
+func (t *traced) StopWithContext(ctx context.Context) (gracefully, stopped bool, err error) {
+	_, t.traced = ctx.Value(spanKey{}).(*fakeSpan)
+	return true, true, nil
+}
+
+func TestWithTracer(t *testing.T) {
+	tracer := &fakeTracer{}
+	module := &traced{sleeper: sleeper{name: "redis"}}
+	loader := NewStarterLoaderWith(WithTracer(tracer), WithStarters(module, &failing{name: "broken"}))
+	_ = loader.Start()
+	if _, err := loader.Stop(time.Second); err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, len(tracer.spans))
+	for i, span := range tracer.spans {
+		names[i] = span.name
+		if !span.ended {
+			t.Fatal("span should be ended", span.name)
+		}
+	}
+	if fmt.Sprint(names) != "[starter.start/redis starter.start/broken starter.stop/redis]" {
+		t.Fatal("unexpected spans", names)
+	}
+	if tracer.spans[0].err != nil || tracer.spans[1].err == nil {
+		t.Fatal("start error should be recorded on the span", tracer.spans[1].err)
+	}
+	if !module.traced {
+		t.Fatal("stop context should carry the span")
+	}
+}
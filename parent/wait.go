@@ -0,0 +1,54 @@
+package parent
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// 等待模块启动时重新检查状态的最长间隔 用于覆盖被丢弃的事件及事件先于状态更新的情况
+const waitRecheckInterval = time.Millisecond * 20
+
+// WaitForStarted 阻塞直到指定的模块均已启动或ctx结束 未指定模块名时等待所有启用的模块
+// 适用于在其他goroutine中执行启动的场景，ctx结束时返回ctx.Err()
+func (s *StarterLoader) WaitForStarted(ctx context.Context, starterNames ...string) error {
+	events := s.Subscribe()
+	defer s.Unsubscribe(events)
+	starters := s.snapshot()
+	if starters.isEmpty() {
+		return errors.New("no starter")
+	}
+	for _, starterName := range starterNames {
+		if starters.find(starterName) == nil {
+			return errors.New("unknown starterName: " + starterName)
+		}
+	}
+	ticker := time.NewTicker(waitRecheckInterval)
+	defer ticker.Stop()
+	for {
+		if s.started(starterNames...) {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-events:
+		case <-ticker.C:
+		}
+	}
+}
+
+// 指定的模块是否均已启动 未指定模块名时判断所有启用的模块
+func (s *StarterLoader) started(starterNames ...string) bool {
+	starters := s.snapshot()
+	if len(starterNames) == 0 {
+		return starters.allStarted()
+	}
+	for _, starterName := range starterNames {
+		wrapper := starters.find(starterName)
+		if wrapper == nil || wrapper.getStatus() != StarterStatusStarted {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,48 @@
+package parent
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWaitForStarted(t *testing.T) {
+	loader := NewStarterLoader([]Starter{
+		&sleeper{name: "redis", delay: time.Millisecond * 30},
+		&sleeper{name: "gorm", delay: time.Millisecond * 30},
+		&sleeper{name: "gin", delay: time.Millisecond * 300},
+	})
+	go func() {
+		_ = loader.Start()
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := loader.WaitForStarted(ctx, "gorm"); err != nil {
+		t.Fatal(err)
+	}
+	if status, _ := loader.GetStatus("gorm"); status != StarterStatusStarted {
+		t.Fatal("gorm should be started", status)
+	}
+	if status, _ := loader.GetStatus("gin"); status == StarterStatusStarted {
+		t.Fatal("wait should return before unrelated modules finish")
+	}
+	if err := loader.WaitForStarted(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !loader.IsAllStarted() {
+		t.Fatal("all modules should be started")
+	}
+}
+
+func TestWaitForStartedCanceled(t *testing.T) {
+	loader := NewStarterLoader([]Starter{&sleeper{name: "redis"}})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+	if err := loader.WaitForStarted(ctx, "redis"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatal("wait should end with the ctx", err)
+	}
+	if err := loader.WaitForStarted(ctx, "missing"); err == nil {
+		t.Fatal("unknown module should return error")
+	}
+}